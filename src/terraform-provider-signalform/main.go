@@ -9,4 +9,6 @@ func main() {
 	plugin.Serve(&plugin.ServeOpts{
 		ProviderFunc: signalform.Provider,
 	})
+	// Terraform is done with the provider: send what's left of the self metrics
+	signalform.FlushSelfMetrics()
 }
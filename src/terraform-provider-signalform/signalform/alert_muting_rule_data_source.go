@@ -0,0 +1,152 @@
+package signalform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const ALERT_MUTING_API_PATH = "/v2/alertmuting"
+
+func alertMutingRuleDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"description": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return the muting rules whose description contains this text",
+			},
+			"filter": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Only return the muting rules having all these filters",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"property": &schema.Schema{
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "A metric time series dimension or property name",
+						},
+						"property_value": &schema.Schema{
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Value of the property",
+						},
+					},
+				},
+			},
+			"rules": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Muting rules matching the description and the filters",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the muting rule",
+						},
+						"description": &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Description of the muting rule",
+						},
+						"start_time": &schema.Schema{
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Seconds since epoch the muting starts at",
+						},
+						"stop_time": &schema.Schema{
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Seconds since epoch the muting stops at (0 if it never stops)",
+						},
+					},
+				},
+			},
+		},
+
+		Read: alertMutingRuleRead,
+	}
+}
+
+func alertMutingRuleRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+
+	mutingRules, err := getAlertMutingRules(config)
+	if err != nil {
+		return fmt.Errorf("Failed reading the muting rules: %s", err.Error())
+	}
+
+	description := d.Get("description").(string)
+	filters := d.Get("filter").(*schema.Set).List()
+	rules := make([]map[string]interface{}, 0)
+	ids := make([]string, 0)
+	for _, mutingRule := range mutingRules {
+		if ruleDescription, _ := mutingRule["description"].(string); !strings.Contains(ruleDescription, description) {
+			continue
+		}
+		if !mutingRuleHasFilters(mutingRule, filters) {
+			continue
+		}
+		item := make(map[string]interface{})
+		item["id"] = mutingRule["id"]
+		item["description"] = mutingRule["description"]
+		if val, ok := mutingRule["startTime"].(float64); ok {
+			item["start_time"] = int(val / 1000)
+		}
+		if val, ok := mutingRule["stopTime"].(float64); ok {
+			item["stop_time"] = int(val / 1000)
+		}
+		rules = append(rules, item)
+		ids = append(ids, fmt.Sprintf("%s", mutingRule["id"]))
+	}
+
+	d.SetId(fmt.Sprintf("%d", hashcode.String(strings.Join(ids, ","))))
+	d.Set("rules", rules)
+
+	return nil
+}
+
+/*
+  Pages through the muting rules. Unlike the other list endpoints, /v2/alertmuting returns the rules
+  as a plain JSON array, without the count: the last page is the one with fewer than PAGE_LIMIT rules.
+*/
+func getAlertMutingRules(config *signalformConfig) ([]map[string]interface{}, error) {
+	mutingRules := make([]map[string]interface{}, 0)
+	for {
+		pageUrl := fmt.Sprintf("%s?offset=%d&limit=%d", apiUrl(config, ALERT_MUTING_API_PATH), len(mutingRules), PAGE_LIMIT)
+		page := make([]map[string]interface{}, 0)
+		if err := getApiResource(config, pageUrl, config.AuthToken, &page); err != nil {
+			return nil, err
+		}
+		mutingRules = append(mutingRules, page...)
+		if len(page) < PAGE_LIMIT {
+			return mutingRules, nil
+		}
+	}
+}
+
+/*
+  Checks that every wanted filter (property and value) is among the filters of the muting rule
+*/
+func mutingRuleHasFilters(mutingRule map[string]interface{}, filters []interface{}) bool {
+	ruleFilters, _ := mutingRule["filters"].([]interface{})
+	for _, filter := range filters {
+		filter := filter.(map[string]interface{})
+		found := false
+		for _, ruleFilter := range ruleFilters {
+			ruleFilter, ok := ruleFilter.(map[string]interface{})
+			if ok && ruleFilter["property"] == filter["property"] && fmt.Sprintf("%v", ruleFilter["propertyValue"]) == filter["property_value"] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
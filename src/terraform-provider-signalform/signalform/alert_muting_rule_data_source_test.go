@@ -0,0 +1,101 @@
+package signalform
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Response of GET /v2/alertmuting, as returned by SignalFx
+const alertMutingResponse = `[
+  {
+    "created": 1557868778000,
+    "creator": "DvdXbX0AIAA",
+    "description": "Maintenance of clusterA",
+    "filters": [
+      {"NOT": false, "property": "cluster", "propertyValue": "clusterA"},
+      {"NOT": false, "property": "region", "propertyValue": "uswest1"}
+    ],
+    "id": "EBBvVQvAcAA",
+    "lastUpdated": 1557868778000,
+    "lastUpdatedBy": "DvdXbX0AIAA",
+    "startTime": 1557868800000,
+    "stopTime": 1557876000000
+  },
+  {
+    "created": 1557869000000,
+    "creator": "DvdXbX0AIAA",
+    "description": "Noisy detector",
+    "filters": [
+      {"NOT": false, "property": "sf_detectorId", "propertyValue": "DvdXbX0AIAB"}
+    ],
+    "id": "EBBvVQvAcAB",
+    "lastUpdated": 1557869000000,
+    "lastUpdatedBy": "DvdXbX0AIAA",
+    "startTime": 1557869000000,
+    "stopTime": 0
+  }
+]`
+
+func TestGetAlertMutingRules(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/alertmuting", r.URL.Path)
+		assert.Equal(t, "0", r.URL.Query().Get("offset"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, alertMutingResponse)
+	}))
+	defer server.Close()
+
+	mutingRules, err := getAlertMutingRules(&signalformConfig{APIURL: server.URL})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(mutingRules))
+	assert.Equal(t, "EBBvVQvAcAA", mutingRules[0]["id"])
+	assert.Equal(t, "Noisy detector", mutingRules[1]["description"])
+	assert.True(t, mutingRuleHasFilters(mutingRules[0], []interface{}{
+		map[string]interface{}{"property": "region", "property_value": "uswest1"},
+	}))
+}
+
+func TestGetAlertMutingRulesPaging(t *testing.T) {
+	offsets := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offsets = append(offsets, r.URL.Query().Get("offset"))
+		if r.URL.Query().Get("offset") == "0" {
+			// A full page
+			rules := make([]string, PAGE_LIMIT)
+			for i := range rules {
+				rules[i] = fmt.Sprintf(`{"id":"R%d"}`, i)
+			}
+			fmt.Fprint(w, "["+strings.Join(rules, ",")+"]")
+		} else {
+			fmt.Fprint(w, `[{"id":"LAST"}]`)
+		}
+	}))
+	defer server.Close()
+
+	mutingRules, err := getAlertMutingRules(&signalformConfig{APIURL: server.URL})
+	assert.Nil(t, err)
+	assert.Equal(t, PAGE_LIMIT+1, len(mutingRules))
+	assert.Equal(t, []string{"0", fmt.Sprintf("%d", PAGE_LIMIT)}, offsets)
+}
+
+func TestMutingRuleHasFilters(t *testing.T) {
+	mutingRule := map[string]interface{}{
+		"filters": []interface{}{
+			map[string]interface{}{"property": "cluster", "propertyValue": "clusterA"},
+			map[string]interface{}{"property": "region", "propertyValue": "uswest1"},
+		},
+	}
+
+	assert.True(t, mutingRuleHasFilters(mutingRule, []interface{}{}))
+	assert.True(t, mutingRuleHasFilters(mutingRule, []interface{}{
+		map[string]interface{}{"property": "cluster", "property_value": "clusterA"},
+	}))
+	assert.False(t, mutingRuleHasFilters(mutingRule, []interface{}{
+		map[string]interface{}{"property": "cluster", "property_value": "clusterA"},
+		map[string]interface{}{"property": "region", "property_value": "useast1"},
+	}))
+}
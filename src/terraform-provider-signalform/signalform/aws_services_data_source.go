@@ -0,0 +1,86 @@
+package signalform
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Namespaces of the AWS services supported by the SignalFx AWS integration
+var AwsServiceNames = []string{
+	"AWS/ApiGateway",
+	"AWS/AppStream",
+	"AWS/ApplicationELB",
+	"AWS/Athena",
+	"AWS/AutoScaling",
+	"AWS/Billing",
+	"AWS/CloudFront",
+	"AWS/CloudSearch",
+	"AWS/DDoSProtection",
+	"AWS/DMS",
+	"AWS/DX",
+	"AWS/DynamoDB",
+	"AWS/EBS",
+	"AWS/EC2",
+	"AWS/EC2Spot",
+	"AWS/ECS",
+	"AWS/EFS",
+	"AWS/ELB",
+	"AWS/ES",
+	"AWS/ElastiCache",
+	"AWS/ElasticBeanstalk",
+	"AWS/ElasticMapReduce",
+	"AWS/ElasticTranscoder",
+	"AWS/Events",
+	"AWS/Firehose",
+	"AWS/Glue",
+	"AWS/Inspector",
+	"AWS/IoT",
+	"AWS/KMS",
+	"AWS/Kafka",
+	"AWS/Kinesis",
+	"AWS/KinesisAnalytics",
+	"AWS/Lambda",
+	"AWS/Logs",
+	"AWS/ML",
+	"AWS/NATGateway",
+	"AWS/NetworkELB",
+	"AWS/OpsWorks",
+	"AWS/Polly",
+	"AWS/RDS",
+	"AWS/Redshift",
+	"AWS/Route53",
+	"AWS/S3",
+	"AWS/SES",
+	"AWS/SNS",
+	"AWS/SQS",
+	"AWS/SWF",
+	"AWS/SageMaker",
+	"AWS/States",
+	"AWS/StorageGateway",
+	"AWS/WorkSpaces",
+	"WAF",
+}
+
+func awsServicesDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"exclude": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "AWS service namespaces to leave out of the list (e.g. AWS/Billing)",
+			},
+			"services": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Namespaces of the AWS services supported by the SignalFx AWS integration",
+			},
+		},
+
+		Read: awsServicesRead,
+	}
+}
+
+func awsServicesRead(d *schema.ResourceData, meta interface{}) error {
+	return servicesRead(AwsServiceNames, d)
+}
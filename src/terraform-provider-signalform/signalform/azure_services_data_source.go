@@ -0,0 +1,75 @@
+package signalform
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Names of the Azure services supported by the SignalFx Azure integration
+var AzureServiceNames = []string{
+	"microsoft.analysisservices/servers",
+	"microsoft.apimanagement/service",
+	"microsoft.batch/batchaccounts",
+	"microsoft.cache/redis",
+	"microsoft.cognitiveservices/accounts",
+	"microsoft.compute/virtualmachines",
+	"microsoft.compute/virtualmachinescalesets",
+	"microsoft.containerinstance/containergroups",
+	"microsoft.containerservice/managedclusters",
+	"microsoft.customerinsights/hubs",
+	"microsoft.datafactory/datafactories",
+	"microsoft.datafactory/factories",
+	"microsoft.datalakeanalytics/accounts",
+	"microsoft.datalakestore/accounts",
+	"microsoft.dbformysql/servers",
+	"microsoft.dbforpostgresql/servers",
+	"microsoft.devices/iothubs",
+	"microsoft.documentdb/databaseaccounts",
+	"microsoft.eventhub/namespaces",
+	"microsoft.insights/autoscalesettings",
+	"microsoft.kusto/clusters",
+	"microsoft.locationbasedservices/accounts",
+	"microsoft.logic/workflows",
+	"microsoft.network/applicationgateways",
+	"microsoft.network/expressroutecircuits",
+	"microsoft.network/loadbalancers",
+	"microsoft.network/publicipaddresses",
+	"microsoft.notificationhubs/namespaces/notificationhubs",
+	"microsoft.powerbidedicated/capacities",
+	"microsoft.relay/namespaces",
+	"microsoft.search/searchservices",
+	"microsoft.servicebus/namespaces",
+	"microsoft.sql/servers/databases",
+	"microsoft.sql/servers/elasticpools",
+	"microsoft.storage/storageaccounts",
+	"microsoft.streamanalytics/streamingjobs",
+	"microsoft.timeseriesinsights/environments",
+	"microsoft.web/hostingenvironments/multirolepools",
+	"microsoft.web/hostingenvironments/workerpools",
+	"microsoft.web/serverfarms",
+	"microsoft.web/sites",
+}
+
+func azureServicesDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"exclude": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Azure services to leave out of the list (e.g. microsoft.batch/batchaccounts)",
+			},
+			"services": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Names of the Azure services supported by the SignalFx Azure integration",
+			},
+		},
+
+		Read: azureServicesRead,
+	}
+}
+
+func azureServicesRead(d *schema.ResourceData, meta interface{}) error {
+	return servicesRead(AzureServiceNames, d)
+}
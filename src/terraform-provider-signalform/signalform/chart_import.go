@@ -0,0 +1,232 @@
+package signalform
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+/*
+  Importer of the chart resources: the chart is fetched from SignalFx, and copied to the fields of the
+  resource by getFields, so that an existing chart can be adopted without changes in the first plan.
+  chartType is the type of the charts the resource manages, as named by the API (options.type).
+*/
+func chartImporter(chartType string, getFields func(chart map[string]interface{}) map[string]interface{}) *schema.ResourceImporter {
+	return &schema.ResourceImporter{
+		State: func(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+			config, err := resourceConfig(d, meta)
+			if err != nil {
+				return nil, err
+			}
+			chart, err := getChartFromApi(config, fmt.Sprintf("%s/%s", apiUrl(config, CHART_API_PATH), d.Id()))
+			if err != nil {
+				return nil, err
+			}
+			options, _ := chart["options"].(map[string]interface{})
+			if options["type"] != chartType {
+				return nil, fmt.Errorf("The chart %s is of type %v, not %s", d.Id(), options["type"], chartType)
+			}
+
+			fields := getFields(chart)
+			name, _ := chart["name"].(string)
+			fields["name"] = removeNameAffixes(config, name)
+			fields["description"], _ = chart["description"].(string)
+			fields["last_updated"], _ = chart["lastUpdated"].(float64)
+			fields["synced"] = true
+			fields["resource_url"] = CHART_URL
+			for field, value := range fields {
+				if err := d.Set(field, value); err != nil {
+					return nil, fmt.Errorf("Failed importing the field %s of the chart %s: %s", field, d.Id(), err.Error())
+				}
+			}
+			return []*schema.ResourceData{d}, nil
+		},
+	}
+}
+
+func getChartFromApi(config *signalformConfig, url string) (map[string]interface{}, error) {
+	status_code, resp_body, err := sendRequest(config, "GET", url, config.AuthToken, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed fetching the chart %s: %s", url, err.Error())
+	}
+	if status_code != 200 {
+		return nil, fmt.Errorf("For the chart %s SignalFx returned status %d: \n%s", url, status_code, resp_body)
+	}
+	chart := map[string]interface{}{}
+	if err := json.Unmarshal(resp_body, &chart); err != nil {
+		return nil, fmt.Errorf("Failed unmarshaling the chart %s: %s", url, err.Error())
+	}
+	return chart, nil
+}
+
+/*
+  Seconds of a duration in milliseconds returned by the API (0 when missing)
+*/
+func fromApiMilliSeconds(value interface{}) int {
+	ms, _ := value.(float64)
+	return int(ms / 1000)
+}
+
+/*
+  SignalFx time syntax for a range in milliseconds, in the largest unit it's a whole number of
+  (e.g. -2h for 7200000). The inverse of fromRangeToMilliSeconds.
+*/
+func fromMilliSecondsToRange(ms int) string {
+	units := []struct {
+		name string
+		ms   int
+	}{
+		{"w", 7 * 24 * 60 * 60 * 1000},
+		{"d", 24 * 60 * 60 * 1000},
+		{"h", 60 * 60 * 1000},
+	}
+	for _, unit := range units {
+		if ms >= unit.ms && ms%unit.ms == 0 {
+			return fmt.Sprintf("-%d%s", ms/unit.ms, unit.name)
+		}
+	}
+	// Minutes being the smallest unit of the syntax, shorter ranges are rounded
+	minutes := int(math.Max(1, math.Floor(float64(ms)/60000+0.5)))
+	return fmt.Sprintf("-%dm", minutes)
+}
+
+/*
+  Name of a color of PaletteColors, from its index ("" when not found)
+*/
+func getPaletteColorName(value interface{}) string {
+	index, ok := value.(float64)
+	if !ok {
+		return ""
+	}
+	for name, palette_index := range PaletteColors {
+		if float64(palette_index) == index {
+			return name
+		}
+	}
+	return ""
+}
+
+/*
+  Name of a legend property from the API, the inverse of getLegendProperty
+*/
+func getLegendPropertyFromApi(property string) string {
+	if property == "sf_originatingMetric" {
+		return "metric"
+	} else if property == "sf_metric" {
+		return "plot_label"
+	}
+	return property
+}
+
+/*
+  Sets the fields of the program options of a chart: minimum_resolution, max_delay, timezone and
+  disable_sampling
+*/
+func setProgramOptionsFromApi(options map[string]interface{}, fields map[string]interface{}) {
+	programOptions, _ := options["programOptions"].(map[string]interface{})
+	fields["minimum_resolution"] = fromApiMilliSeconds(programOptions["minimumResolution"])
+	fields["max_delay"] = fromApiMilliSeconds(programOptions["maxDelay"])
+	fields["timezone"], _ = programOptions["timezone"].(string)
+	fields["disable_sampling"], _ = programOptions["disableSampling"].(bool)
+}
+
+/*
+  legend_options_fields of a chart, in the order of the legend
+*/
+func getLegendOptionsFieldsFromApi(options map[string]interface{}) []interface{} {
+	legendOptions, _ := options["legendOptions"].(map[string]interface{})
+	api_fields, _ := legendOptions["fields"].([]interface{})
+	fields := make([]interface{}, 0)
+	for _, field := range api_fields {
+		field, ok := field.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		property, _ := field["property"].(string)
+		enabled, _ := field["enabled"].(bool)
+		fields = append(fields, map[string]interface{}{
+			"property": getLegendPropertyFromApi(property),
+			"enabled":  enabled,
+		})
+	}
+	return fields
+}
+
+/*
+  Returns time_range for a relative time (e.g. -1h to Now), or start_time and end_time in seconds
+*/
+func getChartTimeFromApi(value interface{}) (string, int, int) {
+	time, ok := value.(map[string]interface{})
+	if !ok {
+		return "", 0, 0
+	}
+	if time["type"] == "relative" {
+		if ms, ok := time["range"].(float64); ok && ms > 0 {
+			return fromMilliSecondsToRange(int(ms)), 0, 0
+		}
+		return "", 0, 0
+	}
+	return "", fromApiMilliSeconds(time["start"]), fromApiMilliSeconds(time["end"])
+}
+
+/*
+  viz_options of a list or single value chart, from its publishLabelOptions
+*/
+func getPerSignalVizOptionsFromApi(options map[string]interface{}) []interface{} {
+	api_viz, _ := options["publishLabelOptions"].([]interface{})
+	viz := make([]interface{}, 0)
+	for _, option := range api_viz {
+		if option, ok := option.(map[string]interface{}); ok {
+			viz = append(viz, getVizOptionFromApi(option))
+		}
+	}
+	return viz
+}
+
+/*
+  The fields of a viz_options block common to all charts; time charts add their own
+*/
+func getVizOptionFromApi(option map[string]interface{}) map[string]interface{} {
+	item := make(map[string]interface{})
+
+	item["label"], _ = option["label"].(string)
+	item["display_name"], _ = option["displayName"].(string)
+	item["color"] = getPaletteColorName(option["paletteIndex"])
+	item["value_unit"], _ = option["valueUnit"].(string)
+	item["value_prefix"], _ = option["valuePrefix"].(string)
+	item["value_suffix"], _ = option["valueSuffix"].(string)
+
+	return item
+}
+
+/*
+  color_scale of a chart. Unset boundaries get the default of the schema (math.MaxFloat32).
+*/
+func getColorScaleFromApi(value interface{}) []interface{} {
+	api_scale, _ := value.([]interface{})
+	scale := make([]interface{}, 0)
+	for _, api_range := range api_scale {
+		api_range, ok := api_range.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		item := make(map[string]interface{})
+
+		for _, boundary := range []string{"gt", "gte", "lt", "lte"} {
+			if value, ok := api_range[boundary].(float64); ok {
+				item[boundary] = value
+			} else {
+				item[boundary] = float64(math.MaxFloat32)
+			}
+		}
+		item["color"] = ""
+		if index, ok := api_range["paletteIndex"].(float64); ok && int(index) >= 0 && int(index) < len(ChartColorsSlice) {
+			item["color"] = ChartColorsSlice[int(index)].name
+		}
+
+		scale = append(scale, item)
+	}
+	return scale
+}
@@ -0,0 +1,193 @@
+package signalform
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChartImporter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/chart/CHARTID", r.URL.Path)
+		fmt.Fprint(w, `{"id":"CHARTID","name":"My chart","lastUpdated":1500000000000,"options":{"type":"Text","markdown":"# Hi"}}`)
+	}))
+	defer server.Close()
+	config := &signalformConfig{APIURL: server.URL}
+
+	d := textChartResource().TestResourceData()
+	d.SetId("CHARTID")
+	imported, err := chartImporter("Text", getTextChartFieldsFromApi).State(d, config)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(imported))
+
+	_, err = chartImporter("List", getListChartFieldsFromApi).State(d, config)
+	assert.Contains(t, err.Error(), "The chart CHARTID is of type Text, not List")
+}
+
+func TestGetChartFromApiNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+	}))
+	defer server.Close()
+
+	_, err := getChartFromApi(&signalformConfig{}, server.URL+"/v2/chart/CHARTID")
+	assert.Contains(t, err.Error(), "SignalFx returned status 404")
+}
+
+func TestFromMilliSecondsToRange(t *testing.T) {
+	assert.Equal(t, "-15m", fromMilliSecondsToRange(15*60*1000))
+	assert.Equal(t, "-90m", fromMilliSecondsToRange(90*60*1000))
+	assert.Equal(t, "-2h", fromMilliSecondsToRange(2*60*60*1000))
+	assert.Equal(t, "-1d", fromMilliSecondsToRange(24*60*60*1000))
+	assert.Equal(t, "-2w", fromMilliSecondsToRange(14*24*60*60*1000))
+	assert.Equal(t, "-1m", fromMilliSecondsToRange(10*1000))
+
+	for _, value := range []string{"-15m", "-2h", "-1d", "-2w"} {
+		ms, err := fromRangeToMilliSeconds(value)
+		assert.Nil(t, err)
+		assert.Equal(t, value, fromMilliSecondsToRange(ms))
+	}
+}
+
+func TestGetChartTimeFromApi(t *testing.T) {
+	time_range, start_time, end_time := getChartTimeFromApi(map[string]interface{}{"type": "relative", "range": float64(3600000)})
+	assert.Equal(t, "-1h", time_range)
+	assert.Equal(t, 0, start_time)
+	assert.Equal(t, 0, end_time)
+
+	time_range, start_time, end_time = getChartTimeFromApi(map[string]interface{}{"type": "absolute", "start": float64(1500000000000), "end": float64(1500003600000)})
+	assert.Equal(t, "", time_range)
+	assert.Equal(t, 1500000000, start_time)
+	assert.Equal(t, 1500003600, end_time)
+
+	time_range, start_time, end_time = getChartTimeFromApi(nil)
+	assert.Equal(t, "", time_range)
+	assert.Equal(t, 0, start_time+end_time)
+}
+
+func TestGetPaletteColorName(t *testing.T) {
+	assert.Equal(t, "orange", getPaletteColorName(float64(5)))
+	assert.Equal(t, "", getPaletteColorName(float64(42)))
+	assert.Equal(t, "", getPaletteColorName(nil))
+}
+
+func TestGetLegendOptionsFieldsFromApi(t *testing.T) {
+	options := map[string]interface{}{
+		"legendOptions": map[string]interface{}{
+			"fields": []interface{}{
+				map[string]interface{}{"property": "sf_originatingMetric", "enabled": true},
+				map[string]interface{}{"property": "host", "enabled": false},
+				map[string]interface{}{"property": "sf_metric", "enabled": true},
+			},
+		},
+	}
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"property": "metric", "enabled": true},
+		map[string]interface{}{"property": "host", "enabled": false},
+		map[string]interface{}{"property": "plot_label", "enabled": true},
+	}, getLegendOptionsFieldsFromApi(options))
+	assert.Equal(t, []interface{}{}, getLegendOptionsFieldsFromApi(map[string]interface{}{}))
+}
+
+func TestGetPerSignalVizOptionsFromApi(t *testing.T) {
+	options := map[string]interface{}{
+		"publishLabelOptions": []interface{}{
+			map[string]interface{}{"label": "A", "displayName": "Requests", "paletteIndex": float64(1), "valueSuffix": "/s"},
+		},
+	}
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"label":        "A",
+			"display_name": "Requests",
+			"color":        "blue",
+			"value_unit":   "",
+			"value_prefix": "",
+			"value_suffix": "/s",
+		},
+	}, getPerSignalVizOptionsFromApi(options))
+}
+
+func TestGetColorScaleFromApi(t *testing.T) {
+	scale := getColorScaleFromApi([]interface{}{
+		map[string]interface{}{"gte": float64(10), "lt": float64(20), "paletteIndex": float64(14)},
+	})
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"gt":    float64(math.MaxFloat32),
+			"gte":   float64(10),
+			"lt":    float64(20),
+			"lte":   float64(math.MaxFloat32),
+			"color": "green",
+		},
+	}, scale)
+
+	// Sent back as it was read
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"gte": float64(10), "lt": float64(20), "paletteIndex": 14},
+	}, getColorScaleOptionsFromSlice(scale))
+}
+
+func TestGetSingleValueChartFieldsFromApi(t *testing.T) {
+	fields := getSingleValueChartFieldsFromApi(map[string]interface{}{
+		"programText": "data('cpu.utilization').mean().publish()",
+		"options": map[string]interface{}{
+			"type":            "SingleValue",
+			"colorBy":         "Dimension",
+			"refreshInterval": float64(60000),
+			"programOptions":  map[string]interface{}{"maxDelay": float64(30000), "timezone": "Europe/Paris"},
+			"showSparkLine":   true,
+		},
+	})
+	assert.Equal(t, "data('cpu.utilization').mean().publish()", fields["program_text"])
+	assert.Equal(t, "Dimension", fields["color_by"])
+	assert.Equal(t, 60, fields["refresh_interval"])
+	assert.Equal(t, 30, fields["max_delay"])
+	assert.Equal(t, 0, fields["minimum_resolution"])
+	assert.Equal(t, "Europe/Paris", fields["timezone"])
+	assert.Equal(t, true, fields["show_spark_line"])
+	assert.Equal(t, false, fields["is_timestamp_hidden"])
+	assert.Equal(t, []interface{}{}, fields["color_scale"])
+}
+
+func TestGetListChartFieldsFromApi(t *testing.T) {
+	fields := getListChartFieldsFromApi(map[string]interface{}{
+		"programText": "data('cpu.utilization').publish()",
+		"options": map[string]interface{}{
+			"type":             "List",
+			"sortBy":           "-value",
+			"maximumPrecision": float64(3),
+			"programOptions":   map[string]interface{}{"disableSampling": true},
+			"time":             map[string]interface{}{"type": "relative", "range": float64(86400000)},
+		},
+	})
+	assert.Equal(t, "-1d", fields["time_range"])
+	assert.Equal(t, "-value", fields["sort_by"])
+	assert.Equal(t, 3, fields["max_precision"])
+	assert.Equal(t, true, fields["disable_sampling"])
+	assert.Equal(t, 0, fields["refresh_interval"])
+	assert.Equal(t, []interface{}{}, fields["viz_options"])
+}
+
+func TestGetTextChartFieldsFromApi(t *testing.T) {
+	fields := getTextChartFieldsFromApi(map[string]interface{}{
+		"options": map[string]interface{}{"type": "Text", "markdown": "# Runbook"},
+	})
+	assert.Equal(t, map[string]interface{}{"markdown": "# Runbook"}, fields)
+}
+
+func TestChartImportersSet(t *testing.T) {
+	for name, resource := range map[string]*schema.Resource{
+		"time":         timeChartResource(),
+		"list":         listChartResource(),
+		"single value": singleValueChartResource(),
+		"heatmap":      heatmapChartResource(),
+		"text":         textChartResource(),
+	} {
+		assert.NotNil(t, resource.Importer, name)
+	}
+}
@@ -1,16 +1,25 @@
 package signalform
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
+	tfconfig "github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/hashcode"
 	"github.com/hashicorp/terraform/helper/schema"
 )
 
 const (
-	DASHBOARD_API_URL = "https://api.signalfx.com/v2/dashboard"
-	DASHBOARD_URL     = "https://app.signalfx.com/#/dashboard/<id>"
+	DASHBOARD_API_PATH = "/v2/dashboard"
+	DASHBOARD_URL      = DEFAULT_APP_URL + "/#/dashboard/<id>"
 )
 
 func dashboardResource() *schema.Resource {
@@ -27,6 +36,12 @@ func dashboardResource() *schema.Resource {
 				Computed:    true,
 				Description: "Latest timestamp the resource was updated",
 			},
+			"drifted_fields": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Fields of the SignalFx object changed outside of Terraform, found when last refreshed",
+			},
 			"resource_url": &schema.Schema{
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -38,20 +53,87 @@ func dashboardResource() *schema.Resource {
 				Computed:    true,
 				Description: "URL of the dashboard",
 			},
+			"credential": credentialSchema(),
 			"name": &schema.Schema{
 				Type:        schema.TypeString,
 				Required:    true,
 				Description: "Name of the dashboard",
 			},
 			"description": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDescription,
+				Description:  "Description of the dashboard (Optional)",
+			},
+			"dashboard_group": &schema.Schema{
 				Type:        schema.TypeString,
 				Optional:    true,
-				Description: "Description of the dashboard (Optional)",
+				Computed:    true,
+				Description: "The ID of the dashboard group that contains the dashboard. Defaults to the default_dashboard_group_id of the provider, or a new group created by SignalFx",
 			},
-			"dashboard_group": &schema.Schema{
+			"source_dashboard_id": &schema.Schema{
 				Type:        schema.TypeString,
-				Required:    true,
-				Description: "The ID of the dashboard group that contains the dashboard. If an ID is not provided during creation, the dashboard will be placed in a newly created dashboard group",
+				Optional:    true,
+				ForceNew:    true,
+				Description: "ID of a dashboard to clone: its charts are copied, owned by this dashboard and placed like in the source, along with the charts of the configuration",
+			},
+			"inline_chart": inlineChartSchema(),
+			"copied_chart": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Charts copied from the source dashboard",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"chart_id": &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the copy of the chart",
+						},
+						"row": &schema.Schema{
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The row of the chart",
+						},
+						"column": &schema.Schema{
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The column of the chart",
+						},
+						"width": &schema.Schema{
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "How many columns the chart takes up",
+						},
+						"height": &schema.Schema{
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "How many rows the chart takes up",
+						},
+					},
+				},
+			},
+			"protect_from_deletion": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Refuse to delete the dashboard, e.g. on terraform destroy. To delete it, set it to false and apply first. false by default",
+			},
+			"expires_after": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validateExpiresAfter,
+				Description:  "How long the dashboard is kept after being created (e.g. 12h, 3d), for short-lived dashboards like the ones of an incident. The dashboard is tagged with its expiration, for a cleanup job to delete it",
+			},
+			"expires_at": &schema.Schema{
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Seconds since epoch the dashboard expires at, when expires_after is set",
+			},
+			"dashboard_group_created": &schema.Schema{
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether SignalFx created the dashboard group along with the dashboard, in which case it's deleted with the dashboard",
 			},
 			"charts_resolution": &schema.Schema{
 				Type:         schema.TypeString,
@@ -60,23 +142,37 @@ func dashboardResource() *schema.Resource {
 				ValidateFunc: validateChartsResolution,
 			},
 			"time_range": &schema.Schema{
-				Type:          schema.TypeString,
-				Optional:      true,
-				ValidateFunc:  validateSignalfxRelativeTime,
-				Description:   "From when to display data. SignalFx time syntax (e.g. -5m, -1h)",
-				ConflictsWith: []string{"start_time", "end_time"},
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateFunc:     validateSignalfxRelativeTime,
+				DiffSuppressFunc: suppressEquivalentRelativeTime,
+				Description:      "From when to display data. SignalFx time syntax (e.g. -5m, -1h)",
+				ConflictsWith:    []string{"start_time", "end_time"},
 			},
 			"start_time": &schema.Schema{
-				Type:          schema.TypeInt,
-				Optional:      true,
-				Description:   "Seconds since epoch to start the visualization",
-				ConflictsWith: []string{"time_range"},
+				Type:             schema.TypeInt,
+				Optional:         true,
+				DiffSuppressFunc: suppressEquivalentEpoch,
+				Description:      "Seconds since epoch to start the visualization",
+				ConflictsWith:    []string{"time_range"},
 			},
 			"end_time": &schema.Schema{
-				Type:          schema.TypeInt,
-				Optional:      true,
-				Description:   "Seconds since epoch to end the visualization",
-				ConflictsWith: []string{"time_range"},
+				Type:             schema.TypeInt,
+				Optional:         true,
+				DiffSuppressFunc: suppressEquivalentEpoch,
+				Description:      "Seconds since epoch to end the visualization",
+				ConflictsWith:    []string{"time_range"},
+			},
+			"max_delay_override": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "How long (in seconds) the charts of the dashboard wait for late datapoints, overriding their max_delay. Max value 900s (15m)",
+				ValidateFunc: validateMaxDelayValue,
+			},
+			"timezone": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Time zone the charts of the dashboard are shown in (e.g. Europe/Paris), instead of the one of each user",
 			},
 			"tags": &schema.Schema{
 				Type:        schema.TypeList,
@@ -84,6 +180,73 @@ func dashboardResource() *schema.Resource {
 				Elem:        &schema.Schema{Type: schema.TypeString},
 				Description: "Tags associated with the dashboard",
 			},
+			"discovery_options_query": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Query of the infrastructure navigator to match the dashboard to (e.g. _exists_:host)",
+			},
+			"discovery_options_selectors": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Selectors of the infrastructure navigator the dashboard is related to (e.g. sf_key:host)",
+			},
+			"auto_arrange": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Compute the position of the charts without row or column, placing them left to right and top to bottom in the space left by the other charts. false by default",
+			},
+			"ignore_unmanaged_charts": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, the charts added to the dashboard outside of Terraform (e.g. in the UI) are kept as they are, instead of being removed. false by default",
+			},
+			"unmanaged_charts": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the charts of the dashboard that are not in the configuration, found when last refreshed",
+			},
+			"authorized_writer_teams": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Team IDs that can edit the dashboard. When neither teams nor users are set, anyone can",
+			},
+			"authorized_writer_users": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "User IDs that can edit the dashboard. When neither teams nor users are set, anyone can",
+			},
+			"permissions": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Who can read or edit the dashboard. When not set, everybody in the organization can",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"principal_type": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validatePermissionPrincipalType,
+							Description:  "Type of the principal: \"ORG\", \"TEAM\" or \"USER\"",
+						},
+						"principal_id": &schema.Schema{
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "ID of the organization, team or user",
+						},
+						"actions": &schema.Schema{
+							Type:        schema.TypeSet,
+							Required:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validatePermissionAction},
+							Description: "Actions allowed to the principal: \"READ\" and/or \"WRITE\"",
+						},
+					},
+				},
+			},
 			"chart": &schema.Schema{
 				Type:        schema.TypeSet,
 				Optional:    true,
@@ -96,29 +259,42 @@ func dashboardResource() *schema.Resource {
 							Description: "ID of the chart to display",
 						},
 						"row": &schema.Schema{
-							Type:        schema.TypeInt,
-							Optional:    true,
-							Description: "The row to show the chart in (zero-based); if height > 1, this value represents the topmost row of the chart. (greater than or equal to 0)",
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      -1,
+							ValidateFunc: validateChartRow,
+							Description:  "The row to show the chart in (zero-based); if height > 1, this value represents the topmost row of the chart. (greater than or equal to 0). When not set, 0, or computed by auto_arrange",
 						},
 						"column": &schema.Schema{
-							Type:        schema.TypeInt,
-							Optional:    true,
-							Description: "The column to show the chart in (zero-based); this value always represents the leftmost column of the chart. (between 0 and 11)",
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      -1,
+							ValidateFunc: validateChartColumn,
+							Description:  "The column to show the chart in (zero-based); this value always represents the leftmost column of the chart. (between 0 and 11). When not set, 0, or computed by auto_arrange",
 						},
 						"width": &schema.Schema{
-							Type:        schema.TypeInt,
-							Optional:    true,
-							Default:     12,
-							Description: "How many columns (out of a total of 12) the chart should take up. (between 1 and 12)",
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      12,
+							ValidateFunc: validateChartWidth,
+							Description:  "How many columns (out of a total of 12) the chart should take up. (between 1 and 12)",
 						},
 						"height": &schema.Schema{
-							Type:        schema.TypeInt,
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      1,
+							ValidateFunc: validateChartHeight,
+							Description:  "How many rows the chart should take up. (greater than or equal to 1)",
+						},
+						"ignore_filters": &schema.Schema{
+							Type:        schema.TypeBool,
 							Optional:    true,
-							Default:     1,
-							Description: "How many rows the chart should take up. (greater than or equal to 1)",
+							Default:     false,
+							Description: "(false by default) If true, the filters and variables of the dashboard don't apply to the chart, which shows the data of its own program",
 						},
 					},
 				},
+				Set: resourceDashboardChartHash,
 			},
 			"grid": &schema.Schema{
 				Type:        schema.TypeSet,
@@ -133,28 +309,32 @@ func dashboardResource() *schema.Resource {
 							Description: "Charts to use for the grid",
 						},
 						"start_row": &schema.Schema{
-							Type:        schema.TypeInt,
-							Optional:    true,
-							Description: "Starting row number for the grid",
-							Default:     0,
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validateChartRow,
+							Description:  "Starting row number for the grid",
+							Default:      0,
 						},
 						"start_column": &schema.Schema{
-							Type:        schema.TypeInt,
-							Optional:    true,
-							Description: "Starting column number for the grid",
-							Default:     0,
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validateChartColumn,
+							Description:  "Starting column number for the grid",
+							Default:      0,
 						},
 						"width": &schema.Schema{
-							Type:        schema.TypeInt,
-							Optional:    true,
-							Default:     12,
-							Description: "Number of columns (out of a total of 12) each chart should take up. (between 1 and 12)",
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      12,
+							ValidateFunc: validateChartWidth,
+							Description:  "Number of columns (out of a total of 12) each chart should take up. (between 1 and 12)",
 						},
 						"height": &schema.Schema{
-							Type:        schema.TypeInt,
-							Optional:    true,
-							Default:     1,
-							Description: "How many rows each chart should take up. (greater than or equal to 1)",
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      1,
+							ValidateFunc: validateChartHeight,
+							Description:  "How many rows each chart should take up. (greater than or equal to 1)",
 						},
 					},
 				},
@@ -172,28 +352,32 @@ func dashboardResource() *schema.Resource {
 							Description: "Charts to use for the column",
 						},
 						"column": &schema.Schema{
-							Type:        schema.TypeInt,
-							Optional:    true,
-							Description: "Column number for the layout",
-							Default:     0,
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validateChartColumn,
+							Description:  "Column number for the layout",
+							Default:      0,
 						},
 						"start_row": &schema.Schema{
-							Type:        schema.TypeInt,
-							Optional:    true,
-							Description: "Starting row number for the column",
-							Default:     0,
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validateChartRow,
+							Description:  "Starting row number for the column",
+							Default:      0,
 						},
 						"width": &schema.Schema{
-							Type:        schema.TypeInt,
-							Optional:    true,
-							Default:     12,
-							Description: "Number of columns (out of a total of 12) each chart should take up. (between 1 and 12)",
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      12,
+							ValidateFunc: validateChartWidth,
+							Description:  "Number of columns (out of a total of 12) each chart should take up. (between 1 and 12)",
 						},
 						"height": &schema.Schema{
-							Type:        schema.TypeInt,
-							Optional:    true,
-							Default:     1,
-							Description: "How many rows each chart should take up. (greater than or equal to 1)",
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      1,
+							ValidateFunc: validateChartHeight,
+							Description:  "How many rows each chart should take up. (greater than or equal to 1)",
 						},
 					},
 				},
@@ -215,9 +399,10 @@ func dashboardResource() *schema.Resource {
 							Description: "An alias for the dashboard variable. This text will appear as the label for the dropdown field on the dashboard",
 						},
 						"description": &schema.Schema{
-							Type:        schema.TypeString,
-							Optional:    true,
-							Description: "Variable description",
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validateDescription,
+							Description:  "Variable description",
 						},
 						"values": &schema.Schema{
 							Type:        schema.TypeSet,
@@ -249,6 +434,97 @@ func dashboardResource() *schema.Resource {
 							Default:     false,
 							Description: "If true, this variable will only apply to charts with a filter on the named property.",
 						},
+						"apply_if_exists": &schema.Schema{
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "If true, the variable only applies to the metric time series with the property, and the others are still shown. false by default",
+						},
+						"value_link": &schema.Schema{
+							Type:        schema.TypeList,
+							Optional:    true,
+							Description: "Links shown when clicking a value of the variable, to another dashboard or to a URL",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"label": &schema.Schema{
+										Type:        schema.TypeString,
+										Required:    true,
+										Description: "Text of the link",
+									},
+									"dashboard_id": &schema.Schema{
+										Type:        schema.TypeString,
+										Optional:    true,
+										Description: "ID of the dashboard to open, with the variable set to the value clicked. Conflicts with url",
+									},
+									"url": &schema.Schema{
+										Type:        schema.TypeString,
+										Optional:    true,
+										Description: "URL to open; {{value}} is replaced by the value clicked. Conflicts with dashboard_id",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"event_overlay": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Event overlays available on the dashboard, to show events (e.g. deploys) on the charts",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"signal": &schema.Schema{
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Search term for the events: the event type, or the name of the detector for detector events",
+						},
+						"type": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "eventTimeSeries",
+							ValidateFunc: validateEventOverlayType,
+							Description:  "Source of the events: \"eventTimeSeries\" (custom events) or \"detectorEvents\" (alerts of a detector). \"eventTimeSeries\" by default",
+						},
+						"label": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Text shown in the dropdown of the overlays",
+						},
+						"color": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validatePerSignalColor,
+							Description:  "Color of the event markers",
+						},
+						"line": &schema.Schema{
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Show a vertical line for the event across the charts. false by default",
+						},
+						"source": eventOverlaySourceSchema(),
+					},
+				},
+			},
+			"selected_event_overlay": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Event overlays shown by default when the dashboard loads",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"signal": &schema.Schema{
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Search term for the events: the event type, or the name of the detector for detector events",
+						},
+						"type": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "eventTimeSeries",
+							ValidateFunc: validateEventOverlayType,
+							Description:  "Source of the events: \"eventTimeSeries\" (custom events) or \"detectorEvents\" (alerts of a detector). \"eventTimeSeries\" by default",
+						},
+						"source": eventOverlaySourceSchema(),
 					},
 				},
 			},
@@ -275,11 +551,20 @@ func dashboardResource() *schema.Resource {
 							Elem:        &schema.Schema{Type: schema.TypeString},
 							Description: "List of strings (which will be treated as an OR filter on the property)",
 						},
+						"apply_if_exists": &schema.Schema{
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "If true, the filter only applies to the metric time series with the property, and the others are still shown. false by default",
+						},
 					},
 				},
 			},
 		},
 
+		SchemaVersion: 1,
+		MigrateState:  dashboardMigrateState,
+
 		Create: dashboardCreate,
 		Read:   dashboardRead,
 		Update: dashboardUpdate,
@@ -287,21 +572,64 @@ func dashboardResource() *schema.Resource {
 	}
 }
 
+/*
+  Filter on the dimensions or properties of the events of an overlay
+*/
+func eventOverlaySourceSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Description: "Filter on the event dimensions or properties",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"property": &schema.Schema{
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "An event dimension or property name",
+				},
+				"values": &schema.Schema{
+					Type:        schema.TypeSet,
+					Required:    true,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+					Description: "List of strings (which will be treated as an OR filter on the property)",
+				},
+				"negated": &schema.Schema{
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+					Description: "(false by default) Whether this filter should be a \"not\" filter",
+				},
+			},
+		},
+	}
+}
+
 /*
   Use Resource object to construct json payload in order to create a dashboard
 */
-func getPayloadDashboard(d *schema.ResourceData) ([]byte, error) {
+func getPayloadDashboard(d *schema.ResourceData, config *signalformConfig) ([]byte, error) {
+	if err := checkTimeSpan(d); err != nil {
+		return nil, err
+	}
+
 	payload := map[string]interface{}{
 		"name":        d.Get("name").(string),
 		"description": d.Get("description").(string),
-		"groupId":     d.Get("dashboard_group").(string),
+	}
+	// Without a group, SignalFx creates one for the dashboard
+	if groupId := getDashboardGroupId(d, config); groupId != "" {
+		payload["groupId"] = groupId
 	}
 
 	all_filters := make(map[string]interface{})
 	if filters := getDashboardFilters(d); len(filters) > 0 {
 		all_filters["sources"] = filters
 	}
-	if variables := getDashboardVariables(d); len(variables) > 0 {
+	variables, err := getDashboardVariables(d)
+	if err != nil {
+		return nil, err
+	}
+	if len(variables) > 0 {
 		all_filters["variables"] = variables
 	}
 	if time := getDashboardTime(d); len(time) > 0 {
@@ -311,23 +639,73 @@ func getPayloadDashboard(d *schema.ResourceData) ([]byte, error) {
 		payload["filters"] = all_filters
 	}
 
-	charts := getDashboardCharts(d)
+	charts := append(getDashboardCharts(d), getDashboardCopiedCharts(d)...)
+	charts = append(charts, getDashboardInlineCharts(d)...)
 	column_charts := getDashboardColumns(d)
 	dashboard_charts := append(charts, column_charts...)
 	grid_charts := getDashboardGrids(d)
 	dashboard_charts = append(dashboard_charts, grid_charts...)
+	if d.Get("auto_arrange").(bool) {
+		arrangeCharts(dashboard_charts)
+	}
+	if err := checkOverlappingCharts(dashboard_charts); err != nil {
+		return nil, err
+	}
+	// Not set means the top left corner, where SignalFx places the chart in the first space available
+	for _, chart := range dashboard_charts {
+		if chart["row"].(int) < 0 {
+			chart["row"] = 0
+		}
+		if chart["column"].(int) < 0 {
+			chart["column"] = 0
+		}
+	}
 	if len(dashboard_charts) > 0 {
 		payload["charts"] = dashboard_charts
 	}
 
+	if overlays := getDashboardEventOverlays(d); len(overlays) > 0 {
+		payload["eventOverlays"] = overlays
+	}
+
+	if overlays := getDashboardSelectedEventOverlays(d); len(overlays) > 0 {
+		payload["selectedEventOverlays"] = overlays
+	}
+
+	if permissions := getDashboardPermissions(d); len(permissions) > 0 {
+		payload["permissions"] = permissions
+	}
+
+	if writers := getAuthorizedWriters(d); writers != nil {
+		payload["authorizedWriters"] = writers
+	}
+
+	if val, ok := d.GetOk("max_delay_override"); ok {
+		payload["maxDelayOverride"] = val.(int) * 1000
+	}
+
+	if val, ok := d.GetOk("timezone"); ok {
+		payload["timezone"] = val.(string)
+	}
+
+	if discovery := getDashboardDiscoveryOptions(d); len(discovery) > 0 {
+		payload["discoveryOptions"] = discovery
+	}
+
 	if chartsResolution, ok := d.GetOk("charts_resolution"); ok {
 		payload["chartDensity"] = strings.ToUpper(chartsResolution.(string))
 	}
+	tags := []string{}
 	if val, ok := d.GetOk("tags"); ok {
-		tags := []string{}
 		for _, tag := range val.([]interface{}) {
 			tags = append(tags, tag.(string))
 		}
+	}
+	// Shows the expiration in the UI, and lets other tools find the expired dashboards
+	if expires_at := d.Get("expires_at").(int); expires_at > 0 {
+		tags = append(tags, expirationTag(expires_at))
+	}
+	if len(tags) > 0 {
 		payload["tags"] = tags
 	}
 
@@ -366,70 +744,306 @@ func getDashboardCharts(d *schema.ResourceData) []map[string]interface{} {
 		item["column"] = chart["column"].(int)
 		item["height"] = chart["height"].(int)
 		item["width"] = chart["width"].(int)
+		if chart["ignore_filters"].(bool) {
+			item["ignoreFilters"] = true
+		}
 
 		charts_list[i] = item
 	}
 	return charts_list
 }
 
-func getDashboardColumns(d *schema.ResourceData) []map[string]interface{} {
-	columns := d.Get("column").(*schema.Set).List()
-	charts := make([]map[string]interface{}, 0)
-	for _, column := range columns {
-		column := column.(map[string]interface{})
+/*
+  Hashes the chart blocks by chart ID only, since a chart is shown once in a dashboard: moving or resizing
+  it shows up in the plan as a change of that chart, instead of one chart removed and one added. The IDs
+  of the charts created in the same apply are unknown at plan time though, and the same for all of them:
+  those blocks are hashed on all their fields, or the set would keep only one of them.
+*/
+func resourceDashboardChartHash(v interface{}) int {
+	chart := v.(map[string]interface{})
+	chart_id, _ := chart["chart_id"].(string)
+	if chart_id != "" && chart_id != tfconfig.UnknownVariableValue {
+		return hashcode.String(chart_id)
+	}
+	var buf bytes.Buffer
+	for _, field := range []string{"chart_id", "row", "column", "width", "height", "ignore_filters"} {
+		buf.WriteString(fmt.Sprintf("%v-", chart[field]))
+	}
+	return hashcode.String(buf.String())
+}
 
-		current_row := column["start_row"].(int)
-		column_number := column["column"].(int)
-		width := column["width"].(int)
-		height := column["height"].(int)
-		for _, chart_id := range column["chart_ids"].([]interface{}) {
-			item := make(map[string]interface{})
+func getDashboardCopiedCharts(d *schema.ResourceData) []map[string]interface{} {
+	copied := d.Get("copied_chart").([]interface{})
+	charts_list := make([]map[string]interface{}, len(copied))
+	for i, chart := range copied {
+		chart := chart.(map[string]interface{})
+		item := make(map[string]interface{})
 
-			item["chartId"] = chart_id.(string)
-			item["height"] = height
-			item["width"] = width
-			item["column"] = column_number
-			item["row"] = current_row
+		item["chartId"] = chart["chart_id"].(string)
+		item["row"] = chart["row"].(int)
+		item["column"] = chart["column"].(int)
+		item["height"] = chart["height"].(int)
+		item["width"] = chart["width"].(int)
 
-			current_row++
-			charts = append(charts, item)
-		}
+		charts_list[i] = item
 	}
-	return charts
+	return charts_list
 }
 
-func getDashboardGrids(d *schema.ResourceData) []map[string]interface{} {
-	grids := d.Get("grid").(*schema.Set).List()
-	charts := make([]map[string]interface{}, 0)
-	for _, grid := range grids {
-		grid := grid.(map[string]interface{})
+/*
+  Copies the charts of the source dashboard, returning the copies at the same place as the originals
+*/
+func copyDashboardCharts(config *signalformConfig, sourceId string) ([]interface{}, error) {
+	source := map[string]interface{}{}
+	if err := getApiResource(config, fmt.Sprintf("%s/%s", apiUrl(config, DASHBOARD_API_PATH), sourceId), config.AuthToken, &source); err != nil {
+		return nil, fmt.Errorf("Failed reading the source dashboard %s: %s", sourceId, err.Error())
+	}
+	source_charts, _ := source["charts"].([]interface{})
+	copied := make([]interface{}, 0)
+	for _, source_chart := range source_charts {
+		source_chart, ok := source_chart.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		chart_id, err := copyChart(config, source_chart["chartId"].(string))
+		if err != nil {
+			return copied, err
+		}
+		item := map[string]interface{}{"chart_id": chart_id}
+		for _, field := range []string{"row", "column", "width", "height"} {
+			value, _ := source_chart[field].(float64)
+			item[field] = int(value)
+		}
+		copied = append(copied, item)
+	}
+	return copied, nil
+}
 
-		current_row := grid["start_row"].(int)
-		current_column := grid["start_column"].(int)
-		width := grid["width"].(int)
-		height := grid["height"].(int)
-		for _, chart_id := range grid["chart_ids"].([]interface{}) {
-			item := make(map[string]interface{})
+/*
+  Creates a copy of a chart, returning its ID
+*/
+func copyChart(config *signalformConfig, chartId string) (string, error) {
+	chart := map[string]interface{}{}
+	if err := getApiResource(config, fmt.Sprintf("%s/%s", apiUrl(config, CHART_API_PATH), chartId), config.AuthToken, &chart); err != nil {
+		return "", fmt.Errorf("Failed reading the chart %s to copy: %s", chartId, err.Error())
+	}
+	for _, field := range ApiReadOnlyFields {
+		delete(chart, field)
+	}
+	payload, err := json.Marshal(chart)
+	if err != nil {
+		return "", fmt.Errorf("Failed creating json payload: %s", err.Error())
+	}
+	status_code, resp_body, err := sendRequest(config, "POST", apiUrl(config, CHART_API_PATH), config.AuthToken, payload)
+	if err != nil {
+		return "", fmt.Errorf("Failed copying the chart %s: %s", chartId, err.Error())
+	}
+	if status_code != 200 {
+		return "", fmt.Errorf("For the copy of the chart %s SignalFx returned status %d: \n%s", chartId, status_code, resp_body)
+	}
+	created := map[string]interface{}{}
+	if err := json.Unmarshal(resp_body, &created); err != nil {
+		return "", fmt.Errorf("Failed unmarshaling the copy of the chart %s: %s", chartId, err.Error())
+	}
+	return created["id"].(string), nil
+}
 
-			item["chartId"] = chart_id.(string)
-			item["height"] = height
-			item["width"] = width
+/*
+  Deletes charts owned by the dashboard (copied from the source dashboard or inline)
+*/
+func deleteOwnedCharts(config *signalformConfig, copied []interface{}) error {
+	for _, chart := range copied {
+		chart_id := chart.(map[string]interface{})["chart_id"].(string)
+		url := fmt.Sprintf("%s/%s", apiUrl(config, CHART_API_PATH), chart_id)
+		status_code, resp_body, err := sendRequest(config, "DELETE", url, config.AuthToken, nil)
+		if err != nil {
+			return fmt.Errorf("Failed deleting the chart %s of the dashboard: %s", chart_id, err.Error())
+		}
+		if status_code >= 400 && status_code != 404 {
+			return fmt.Errorf("For the chart %s of the dashboard SignalFx returned status %d: \n%s", chart_id, status_code, resp_body)
+		}
+	}
+	return nil
+}
 
-			if current_column+width > 12 {
-				current_row += 1
-				current_column = grid["start_column"].(int)
+/*
+  Places the charts without row or column (-1), in order, in the first space where they fit, scanning the
+  dashboard left to right and top to bottom. The charts with both set are never moved; a chart with only
+  the column set stays in that column, and one with only the row set goes in that row or the first one
+  below where it fits.
+*/
+func arrangeCharts(charts []map[string]interface{}) {
+	taken := make(map[[2]int]bool)
+	occupy := func(chart map[string]interface{}) {
+		for row := chart["row"].(int); row < chart["row"].(int)+chart["height"].(int); row++ {
+			for column := chart["column"].(int); column < chart["column"].(int)+chart["width"].(int); column++ {
+				taken[[2]int{row, column}] = true
 			}
-			item["row"] = current_row
-			item["column"] = current_column
-
-			current_column += width
-			charts = append(charts, item)
 		}
 	}
-	return charts
-}
+	fits := func(row int, column int, width int, height int) bool {
+		for r := row; r < row+height; r++ {
+			for c := column; c < column+width; c++ {
+				if taken[[2]int{r, c}] {
+					return false
+				}
+			}
+		}
+		return true
+	}
 
-func getDashboardVariables(d *schema.ResourceData) []map[string]interface{} {
+	for _, chart := range charts {
+		if chart["row"].(int) >= 0 && chart["column"].(int) >= 0 {
+			occupy(chart)
+		}
+	}
+	for _, chart := range charts {
+		if chart["row"].(int) >= 0 && chart["column"].(int) >= 0 {
+			continue
+		}
+		width, height := chart["width"].(int), chart["height"].(int)
+		columns := []int{}
+		if chart["column"].(int) >= 0 {
+			columns = append(columns, chart["column"].(int))
+		} else {
+			for column := 0; column+width <= 12; column++ {
+				columns = append(columns, column)
+			}
+		}
+		row := 0
+		if chart["row"].(int) >= 0 {
+			row = chart["row"].(int)
+		}
+		if len(columns) == 0 {
+			// Wider than the dashboard: SignalFx rejects it, let it tell why
+			chart["row"] = row
+			chart["column"] = 0
+			continue
+		}
+
+		for placed := false; !placed; row++ {
+			for _, column := range columns {
+				if fits(row, column, width, height) {
+					chart["row"] = row
+					chart["column"] = column
+					placed = true
+					break
+				}
+			}
+		}
+		occupy(chart)
+	}
+}
+
+/*
+  Fails when two charts take up the same cells of the dashboard, listing the charts that collide. The
+  charts without row or column are left to SignalFx, which moves them to the first space available.
+  Terraform 0.10 has no CustomizeDiff, so this happens when the dashboard is created or updated.
+*/
+func checkOverlappingCharts(charts []map[string]interface{}) error {
+	owners := make(map[[2]int]string)
+	collisions := []string{}
+	seen := make(map[string]bool)
+	for _, chart := range charts {
+		if chart["row"].(int) < 0 || chart["column"].(int) < 0 {
+			continue
+		}
+		chart_id := chart["chartId"].(string)
+		for row := chart["row"].(int); row < chart["row"].(int)+chart["height"].(int); row++ {
+			for column := chart["column"].(int); column < chart["column"].(int)+chart["width"].(int); column++ {
+				cell := [2]int{row, column}
+				if owner, ok := owners[cell]; ok {
+					collision := fmt.Sprintf("%s and %s (row %d, column %d)", owner, chart_id, row, column)
+					if !seen[owner+" "+chart_id] {
+						seen[owner+" "+chart_id] = true
+						collisions = append(collisions, collision)
+					}
+					continue
+				}
+				owners[cell] = chart_id
+			}
+		}
+	}
+	if len(collisions) > 0 {
+		return fmt.Errorf("Overlapping charts in the dashboard: %s", strings.Join(collisions, ", "))
+	}
+	return nil
+}
+
+func getDashboardColumns(d *schema.ResourceData) []map[string]interface{} {
+	columns := d.Get("column").(*schema.Set).List()
+	charts := make([]map[string]interface{}, 0)
+	for _, column := range columns {
+		column := column.(map[string]interface{})
+
+		column_charts := getColumnCharts(column["chart_ids"].([]interface{}), column["start_row"].(int), column["column"].(int), column["width"].(int), column["height"].(int))
+		charts = append(charts, column_charts...)
+	}
+	return charts
+}
+
+/*
+  Stacks the charts of a column one below the other, starting from start_row
+*/
+func getColumnCharts(chart_ids []interface{}, start_row int, column_number int, width int, height int) []map[string]interface{} {
+	charts := make([]map[string]interface{}, 0)
+	current_row := start_row
+	for _, chart_id := range chart_ids {
+		item := make(map[string]interface{})
+
+		item["chartId"] = chart_id.(string)
+		item["height"] = height
+		item["width"] = width
+		item["column"] = column_number
+		item["row"] = current_row
+
+		current_row += height
+		charts = append(charts, item)
+	}
+	return charts
+}
+
+func getDashboardGrids(d *schema.ResourceData) []map[string]interface{} {
+	grids := d.Get("grid").(*schema.Set).List()
+	charts := make([]map[string]interface{}, 0)
+	for _, grid := range grids {
+		grid := grid.(map[string]interface{})
+
+		grid_charts := getGridCharts(grid["chart_ids"].([]interface{}), grid["start_row"].(int), grid["start_column"].(int), grid["width"].(int), grid["height"].(int))
+		charts = append(charts, grid_charts...)
+	}
+	return charts
+}
+
+/*
+  Places the charts of a grid one after another by row. A chart that doesn't fit in the 12 columns goes
+  to the next row, below the tallest charts of the current one (all the charts of a grid have the same height).
+*/
+func getGridCharts(chart_ids []interface{}, start_row int, start_column int, width int, height int) []map[string]interface{} {
+	charts := make([]map[string]interface{}, 0)
+	current_row := start_row
+	current_column := start_column
+	for _, chart_id := range chart_ids {
+		item := make(map[string]interface{})
+
+		item["chartId"] = chart_id.(string)
+		item["height"] = height
+		item["width"] = width
+
+		if current_column+width > 12 {
+			current_row += height
+			current_column = start_column
+		}
+		item["row"] = current_row
+		item["column"] = current_column
+
+		current_column += width
+		charts = append(charts, item)
+	}
+	return charts
+}
+
+func getDashboardVariables(d *schema.ResourceData) ([]map[string]interface{}, error) {
 	variables := d.Get("variable").(*schema.Set).List()
 	vars_list := make([]map[string]interface{}, len(variables))
 	for i, variable := range variables {
@@ -459,10 +1073,133 @@ func getDashboardVariables(d *schema.ResourceData) []map[string]interface{} {
 		item["restricted"] = variable["restricted_suggestions"].(bool)
 
 		item["replaceOnly"] = variable["replace_only"].(bool)
+		item["applyIfExists"] = variable["apply_if_exists"].(bool)
+		links, err := getVariableValueLinks(variable["value_link"].([]interface{}))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value_link in the variable %s: %s", variable["property"], err.Error())
+		}
+		if len(links) > 0 {
+			item["valueLinks"] = links
+		}
 
 		vars_list[i] = item
 	}
-	return vars_list
+	return vars_list, nil
+}
+
+/*
+  Targets of the value links of a variable, in the format of the data links of SignalFx: a dashboard
+  (SignalFxDashboard) or a URL (ExternalUrl)
+*/
+func getVariableValueLinks(links []interface{}) ([]map[string]interface{}, error) {
+	links_list := make([]map[string]interface{}, len(links))
+	for i, link := range links {
+		link := link.(map[string]interface{})
+		dashboard_id := link["dashboard_id"].(string)
+		url := link["url"].(string)
+		if (dashboard_id == "") == (url == "") {
+			return nil, fmt.Errorf("the link %s must have either a dashboard_id or a url", link["label"])
+		}
+		item := map[string]interface{}{
+			"name": link["label"].(string),
+		}
+		if dashboard_id != "" {
+			item["type"] = "SignalFxDashboard"
+			item["dashboardId"] = dashboard_id
+		} else {
+			item["type"] = "ExternalUrl"
+			item["url"] = url
+		}
+		links_list[i] = item
+	}
+	return links_list, nil
+}
+
+func getDashboardEventOverlays(d *schema.ResourceData) []map[string]interface{} {
+	overlays := d.Get("event_overlay").([]interface{})
+	overlay_list := make([]map[string]interface{}, len(overlays))
+	for i, overlay := range overlays {
+		overlay := overlay.(map[string]interface{})
+		item := make(map[string]interface{})
+
+		item["eventSignal"] = map[string]interface{}{
+			"eventSearchText": overlay["signal"].(string),
+			"eventType":       overlay["type"].(string),
+		}
+		if val, ok := overlay["label"].(string); ok && val != "" {
+			item["label"] = val
+		}
+		if val, ok := overlay["color"].(string); ok {
+			if elem, ok := PaletteColors[val]; ok {
+				item["eventColorIndex"] = elem
+			}
+		}
+		item["eventLine"] = overlay["line"].(bool)
+		item["sources"] = getEventOverlaySources(overlay["source"].([]interface{}))
+
+		overlay_list[i] = item
+	}
+	return overlay_list
+}
+
+func getDashboardSelectedEventOverlays(d *schema.ResourceData) []map[string]interface{} {
+	overlays := d.Get("selected_event_overlay").([]interface{})
+	overlay_list := make([]map[string]interface{}, len(overlays))
+	for i, overlay := range overlays {
+		overlay := overlay.(map[string]interface{})
+		item := make(map[string]interface{})
+
+		item["eventSignal"] = map[string]interface{}{
+			"eventSearchText": overlay["signal"].(string),
+			"eventType":       overlay["type"].(string),
+		}
+		item["sources"] = getEventOverlaySources(overlay["source"].([]interface{}))
+
+		overlay_list[i] = item
+	}
+	return overlay_list
+}
+
+func getEventOverlaySources(sources []interface{}) []map[string]interface{} {
+	source_list := make([]map[string]interface{}, len(sources))
+	for i, source := range sources {
+		source := source.(map[string]interface{})
+		item := make(map[string]interface{})
+
+		item["property"] = source["property"].(string)
+		item["value"] = source["values"].(*schema.Set).List()
+		item["NOT"] = source["negated"].(bool)
+
+		source_list[i] = item
+	}
+	return source_list
+}
+
+func getDashboardDiscoveryOptions(d *schema.ResourceData) map[string]interface{} {
+	discovery := make(map[string]interface{})
+	if val, ok := d.GetOk("discovery_options_query"); ok {
+		discovery["query"] = val.(string)
+	}
+	if selectors := d.Get("discovery_options_selectors").(*schema.Set).List(); len(selectors) > 0 {
+		discovery["selectors"] = selectors
+	}
+	return discovery
+}
+
+func getDashboardPermissions(d *schema.ResourceData) []map[string]interface{} {
+	permissions := d.Get("permissions").(*schema.Set).List()
+	permission_list := make([]map[string]interface{}, len(permissions))
+	for i, permission := range permissions {
+		permission := permission.(map[string]interface{})
+		item := make(map[string]interface{})
+
+		item["principalType"] = permission["principal_type"].(string)
+		item["principalId"] = permission["principal_id"].(string)
+		item["actions"] = permission["actions"].(*schema.Set).List()
+
+		permission_list[i] = item
+	}
+	return permission_list
 }
 
 func getDashboardFilters(d *schema.ResourceData) []map[string]interface{} {
@@ -475,6 +1212,7 @@ func getDashboardFilters(d *schema.ResourceData) []map[string]interface{} {
 		item["property"] = filter["property"].(string)
 		item["NOT"] = filter["negated"].(bool)
 		item["value"] = filter["values"].(*schema.Set).List()
+		item["applyIfExists"] = filter["apply_if_exists"].(bool)
 
 		filter_list[i] = item
 	}
@@ -482,37 +1220,620 @@ func getDashboardFilters(d *schema.ResourceData) []map[string]interface{} {
 }
 
 func dashboardCreate(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
-	payload, err := getPayloadDashboard(d)
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
+	if err := createDashboard(config, d); err != nil {
+		if d.Id() == "" {
+			// Without an ID the dashboard isn't saved in the state, so the charts it owns would be lost
+			owned := append(d.Get("copied_chart").([]interface{}), d.Get("inline_chart").([]interface{})...)
+			if err := deleteOwnedCharts(config, owned); err != nil {
+				log.Printf("[WARN] %s", err.Error())
+			}
+		}
+		return err
+	}
+	if getDashboardGroupId(d, config) != "" || config.DryRun {
+		d.Set("dashboard_group_created", false)
+		return nil
+	}
+
+	// Keep track of the group created by SignalFx, to delete it along with the dashboard
+	dashboard := map[string]interface{}{}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, DASHBOARD_API_PATH), d.Id())
+	if err := getApiResource(config, url, config.AuthToken, &dashboard); err != nil {
+		return fmt.Errorf("Failed reading the dashboard group created for the dashboard %s: %s", d.Get("name"), err.Error())
+	}
+	d.Set("dashboard_group", dashboard["groupId"])
+	d.Set("dashboard_group_created", true)
+	return nil
+}
+
+/*
+  Creates the charts owned by the dashboard, then the dashboard
+*/
+func createDashboard(config *signalformConfig, d *schema.ResourceData) error {
+	if val, ok := d.GetOk("expires_after"); ok {
+		ttl, err := expiresAfterSeconds(val.(string))
+		if err != nil {
+			return err
+		}
+		d.Set("expires_at", int(time.Now().Unix())+ttl)
+	}
+	if sourceId, ok := d.GetOk("source_dashboard_id"); ok && !config.DryRun {
+		copied, err := copyDashboardCharts(config, sourceId.(string))
+		d.Set("copied_chart", copied)
+		if err != nil {
+			return err
+		}
+	}
+	if err := saveInlineCharts(config, d); err != nil {
+		return err
+	}
+	payload, err := getPayloadDashboard(d, config)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
+	if err := checkChartsExist(config, getDashboardChartIds(d)); err != nil {
+		return err
+	}
+
+	return resourceCreate(config, apiUrl(config, DASHBOARD_API_PATH), config.AuthToken, payload, d)
+}
 
-	return resourceCreate(DASHBOARD_API_URL, config.AuthToken, payload, d)
+/*
+  Group of the dashboard: the one in the configuration (or state), else the default of the provider
+*/
+func getDashboardGroupId(d *schema.ResourceData, config *signalformConfig) string {
+	if groupId := d.Get("dashboard_group").(string); groupId != "" {
+		return groupId
+	}
+	return config.DefaultDashboardGroupID
+}
+
+/*
+  IDs of the charts of the chart, grid and column blocks, without duplicates
+*/
+func getDashboardChartIds(d *schema.ResourceData) []string {
+	ids := []string{}
+	seen := make(map[string]bool)
+	add := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, chart := range d.Get("chart").(*schema.Set).List() {
+		add(chart.(map[string]interface{})["chart_id"].(string))
+	}
+	for _, field := range []string{"grid", "column"} {
+		for _, layout := range d.Get(field).(*schema.Set).List() {
+			for _, id := range layout.(map[string]interface{})["chart_ids"].([]interface{}) {
+				add(id.(string))
+			}
+		}
+	}
+	return ids
+}
+
+/*
+  IDs of all the charts the provider manages in the dashboard: the ones of the chart, grid and column
+  blocks and the charts it owns, both before and after the change being applied (a chart just removed
+  from the configuration is still managed)
+*/
+func getManagedChartIds(d *schema.ResourceData) map[string]bool {
+	managed := make(map[string]bool)
+	for _, field := range []string{"chart", "grid", "column", "copied_chart", "inline_chart"} {
+		old, new := d.GetChange(field)
+		for _, value := range []interface{}{old, new} {
+			var blocks []interface{}
+			switch value := value.(type) {
+			case *schema.Set:
+				blocks = value.List()
+			case []interface{}:
+				blocks = value
+			}
+			for _, block := range blocks {
+				block := block.(map[string]interface{})
+				if chart_id, ok := block["chart_id"].(string); ok {
+					managed[chart_id] = true
+				}
+				if chart_ids, ok := block["chart_ids"].([]interface{}); ok {
+					for _, chart_id := range chart_ids {
+						managed[chart_id.(string)] = true
+					}
+				}
+			}
+		}
+	}
+	return managed
+}
+
+/*
+  Charts of the dashboard returned by SignalFx that the provider doesn't manage, e.g. added in the UI
+*/
+func getUnmanagedCharts(api_charts []interface{}, managed map[string]bool) []interface{} {
+	unmanaged := make([]interface{}, 0)
+	for _, chart := range api_charts {
+		if chart, ok := chart.(map[string]interface{}); ok && !managed[fmt.Sprintf("%v", chart["chartId"])] {
+			unmanaged = append(unmanaged, chart)
+		}
+	}
+	return unmanaged
+}
+
+/*
+  Adds the unmanaged charts the dashboard has in SignalFx to the payload, so that sending it keeps them
+*/
+func addUnmanagedCharts(config *signalformConfig, url string, payload []byte, managed map[string]bool) ([]byte, error) {
+	current := map[string]interface{}{}
+	if err := getApiResource(config, url, config.AuthToken, &current); err != nil {
+		return nil, fmt.Errorf("Failed reading the charts of the dashboard: %s", err.Error())
+	}
+	current_charts, _ := current["charts"].([]interface{})
+	unmanaged := getUnmanagedCharts(current_charts, managed)
+	if len(unmanaged) == 0 {
+		return payload, nil
+	}
+	dashboard := map[string]interface{}{}
+	if err := json.Unmarshal(payload, &dashboard); err != nil {
+		return nil, err
+	}
+	charts, _ := dashboard["charts"].([]interface{})
+	dashboard["charts"] = append(charts, unmanaged...)
+	return json.Marshal(dashboard)
+}
+
+/*
+  Fails listing the charts that don't exist in SignalFx, instead of the 400 the API returns for the
+  whole dashboard. The IDs usually come from charts created in the same run, so this can't be done
+  at plan time.
+*/
+func checkChartsExist(config *signalformConfig, ids []string) error {
+	missing := []string{}
+	for _, id := range ids {
+		if isDryRunId(id) {
+			continue
+		}
+		url := fmt.Sprintf("%s/%s", apiUrl(config, CHART_API_PATH), id)
+		status_code, resp_body, err := sendRequest(config, "GET", url, config.AuthToken, nil)
+		if err != nil {
+			return fmt.Errorf("Failed checking the chart %s: %s", id, err.Error())
+		}
+		if status_code == 404 {
+			missing = append(missing, id)
+		} else if status_code != 200 {
+			return fmt.Errorf("For the chart %s SignalFx returned status %d: \n%s", id, status_code, resp_body)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("Charts not found in SignalFx: %s. Check the chart_id and chart_ids of the dashboard", strings.Join(missing, ", "))
+	}
+	return nil
 }
 
 func dashboardRead(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
-	url := fmt.Sprintf("%s/%s", DASHBOARD_API_URL, d.Id())
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
+	// Reading never deletes: expired dashboards are left to whatever reaps the expires: tag
+	if isExpired(d.Get("expires_at").(int), time.Now()) {
+		log.Printf("[WARN] The dashboard %s (%s) expired, remove its block to delete it", d.Get("name"), d.Id())
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, DASHBOARD_API_PATH), d.Id())
 
-	return resourceRead(url, config.AuthToken, d)
+	// The state as it would be sent, to tell what changed outside of Terraform. A state that can't be
+	// sent (e.g. overlapping charts) is always out of sync.
+	payload, err := getPayloadDashboard(d, config)
+	if err != nil {
+		payload = nil
+	}
+	// The charts are compared by the chart blocks read back, since SignalFx also has the unmanaged ones
+	if payload != nil && d.Get("ignore_unmanaged_charts").(bool) {
+		dashboard := map[string]interface{}{}
+		json.Unmarshal(payload, &dashboard)
+		delete(dashboard, "charts")
+		payload, _ = json.Marshal(dashboard)
+	}
+
+	return resourceReadFields(config, url, config.AuthToken, payload, d, func(dashboard map[string]interface{}) error {
+		return setDashboardFields(config, d, dashboard)
+	})
+}
+
+/*
+  Copies the dashboard returned by SignalFx to the fields of the resource. The charts are only read back
+  when they all come from chart blocks: the ones of grid, column and auto_arrange can't be told apart.
+*/
+func setDashboardFields(config *signalformConfig, d *schema.ResourceData, dashboard map[string]interface{}) error {
+	if name, ok := dashboard["name"].(string); ok {
+		d.Set("name", removeNameAffixes(config, name))
+	}
+	description, _ := dashboard["description"].(string)
+	d.Set("description", description)
+
+	filters, _ := dashboard["filters"].(map[string]interface{})
+	sources, _ := filters["sources"].([]interface{})
+	if err := d.Set("filter", getDashboardFiltersFromApi(sources)); err != nil {
+		return err
+	}
+	variables, _ := filters["variables"].([]interface{})
+	if err := d.Set("variable", getDashboardVariablesFromApi(variables)); err != nil {
+		return err
+	}
+	time_range, start_time, end_time := getDashboardTimeFromApi(filters["time"])
+	d.Set("time_range", time_range)
+	d.Set("start_time", start_time)
+	d.Set("end_time", end_time)
+
+	max_delay_override, _ := dashboard["maxDelayOverride"].(float64)
+	d.Set("max_delay_override", int(max_delay_override/1000))
+	timezone, _ := dashboard["timezone"].(string)
+	d.Set("timezone", timezone)
+
+	charts, _ := dashboard["charts"].([]interface{})
+	unmanaged := getUnmanagedCharts(charts, getManagedChartIds(d))
+	unmanaged_ids := make([]string, len(unmanaged))
+	for i, chart := range unmanaged {
+		unmanaged_ids[i] = fmt.Sprintf("%v", chart.(map[string]interface{})["chartId"])
+	}
+	d.Set("unmanaged_charts", unmanaged_ids)
+
+	if d.Get("grid").(*schema.Set).Len() == 0 && d.Get("column").(*schema.Set).Len() == 0 && !d.Get("auto_arrange").(bool) {
+		// The charts owned by the dashboard aren't in chart blocks, nor the unmanaged ones when ignored
+		skipped := make(map[string]bool)
+		for _, chart := range append(d.Get("copied_chart").([]interface{}), d.Get("inline_chart").([]interface{})...) {
+			skipped[chart.(map[string]interface{})["chart_id"].(string)] = true
+		}
+		if d.Get("ignore_unmanaged_charts").(bool) {
+			for _, chart_id := range unmanaged_ids {
+				skipped[chart_id] = true
+			}
+		}
+		configured := make([]interface{}, 0)
+		for _, chart := range charts {
+			if chart, ok := chart.(map[string]interface{}); ok && !skipped[fmt.Sprintf("%v", chart["chartId"])] {
+				configured = append(configured, chart)
+			}
+		}
+		if err := d.Set("chart", getDashboardChartsFromApi(configured, d.Get("chart").(*schema.Set).List())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getDashboardFiltersFromApi(sources []interface{}) []interface{} {
+	filters := make([]interface{}, 0)
+	for _, source := range sources {
+		source, ok := source.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		item := make(map[string]interface{})
+
+		item["property"], _ = source["property"].(string)
+		item["negated"], _ = source["NOT"].(bool)
+		item["values"] = schema.NewSet(schema.HashString, getApiStrings(source["value"]))
+		item["apply_if_exists"], _ = source["applyIfExists"].(bool)
+
+		filters = append(filters, item)
+	}
+	return filters
+}
+
+func getDashboardVariablesFromApi(variables []interface{}) []interface{} {
+	vars := make([]interface{}, 0)
+	for _, variable := range variables {
+		variable, ok := variable.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		item := make(map[string]interface{})
+
+		item["property"], _ = variable["property"].(string)
+		item["alias"], _ = variable["alias"].(string)
+		item["description"], _ = variable["description"].(string)
+		item["values"] = schema.NewSet(schema.HashString, getApiStrings(variable["value"]))
+		item["value_required"], _ = variable["required"].(bool)
+		item["values_suggested"] = schema.NewSet(schema.HashString, getApiStrings(variable["preferredSuggestions"]))
+		item["restricted_suggestions"], _ = variable["restricted"].(bool)
+		item["replace_only"], _ = variable["replaceOnly"].(bool)
+		item["apply_if_exists"], _ = variable["applyIfExists"].(bool)
+		item["value_link"] = getVariableValueLinksFromApi(variable["valueLinks"])
+
+		vars = append(vars, item)
+	}
+	return vars
+}
+
+func getVariableValueLinksFromApi(value interface{}) []interface{} {
+	api_links, _ := value.([]interface{})
+	links := make([]interface{}, 0)
+	for _, link := range api_links {
+		link, ok := link.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		item := make(map[string]interface{})
+		item["label"], _ = link["name"].(string)
+		item["dashboard_id"], _ = link["dashboardId"].(string)
+		item["url"], _ = link["url"].(string)
+
+		links = append(links, item)
+	}
+	return links
+}
+
+/*
+  Returns time_range for a relative time (e.g. -1h to Now), or start_time and end_time in seconds
+*/
+func getDashboardTimeFromApi(value interface{}) (string, int, int) {
+	time, ok := value.(map[string]interface{})
+	if !ok {
+		return "", 0, 0
+	}
+	if start, ok := time["start"].(string); ok {
+		return start, 0, 0
+	}
+	start_time, end_time := 0, 0
+	if start, ok := time["start"].(float64); ok {
+		start_time = int(start / 1000)
+	}
+	if end, ok := time["end"].(float64); ok {
+		end_time = int(end / 1000)
+	}
+	return "", start_time, end_time
+}
+
+/*
+  Charts of the dashboard as chart blocks. A row or column not set in the configuration (-1) stays that
+  way, since it's SignalFx that picked it.
+*/
+func getDashboardChartsFromApi(api_charts []interface{}, tf_charts []interface{}) []interface{} {
+	unset := make(map[string][2]bool)
+	for _, tf_chart := range tf_charts {
+		tf_chart := tf_chart.(map[string]interface{})
+		unset[tf_chart["chart_id"].(string)] = [2]bool{tf_chart["row"].(int) < 0, tf_chart["column"].(int) < 0}
+	}
+
+	charts := make([]interface{}, 0)
+	for _, api_chart := range api_charts {
+		api_chart, ok := api_chart.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		item := make(map[string]interface{})
+
+		chart_id, _ := api_chart["chartId"].(string)
+		item["chart_id"] = chart_id
+		for _, field := range []string{"row", "column", "width", "height"} {
+			value, _ := api_chart[field].(float64)
+			item[field] = int(value)
+		}
+		if unset[chart_id][0] {
+			item["row"] = -1
+		}
+		if unset[chart_id][1] {
+			item["column"] = -1
+		}
+		ignore_filters, _ := api_chart["ignoreFilters"].(bool)
+		item["ignore_filters"] = ignore_filters
+
+		charts = append(charts, item)
+	}
+	return charts
 }
 
 func dashboardUpdate(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
-	payload, err := getPayloadDashboard(d)
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
+	if err := saveInlineCharts(config, d); err != nil {
+		return err
+	}
+	payload, err := getPayloadDashboard(d, config)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
-	url := fmt.Sprintf("%s/%s", DASHBOARD_API_URL, d.Id())
+	if err := checkChartsExist(config, getDashboardChartIds(d)); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, DASHBOARD_API_PATH), d.Id())
+	if err := backupDashboard(config, url, d); err != nil {
+		return err
+	}
+	if d.Get("ignore_unmanaged_charts").(bool) {
+		payload, err = addUnmanagedCharts(config, url, payload, getManagedChartIds(d))
+		if err != nil {
+			return err
+		}
+	}
 
-	return resourceUpdate(url, config.AuthToken, payload, d)
+	if err := resourceUpdate(config, url, config.AuthToken, payload, d); err != nil {
+		return err
+	}
+	// Moved out of the group created by SignalFx, which is left empty
+	if d.HasChange("dashboard_group") && d.Get("dashboard_group_created").(bool) {
+		old_group, _ := d.GetChange("dashboard_group")
+		if err := deleteCreatedDashboardGroup(config, old_group.(string)); err != nil {
+			return err
+		}
+		d.Set("dashboard_group_created", false)
+	}
+	return nil
 }
 
 func dashboardDelete(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
-	url := fmt.Sprintf("%s/%s", DASHBOARD_API_URL, d.Id())
-	return resourceDelete(url, config.AuthToken, d)
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
+	// The value in the state, i.e. the last one applied
+	if d.Get("protect_from_deletion").(bool) {
+		return fmt.Errorf("The dashboard %s (%s) has protect_from_deletion enabled. To delete it, set protect_from_deletion = false and apply, then delete it", d.Get("name"), d.Id())
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, DASHBOARD_API_PATH), d.Id())
+	if err := backupDashboard(config, url, d); err != nil {
+		return err
+	}
+	if config.ArchiveDashboardGroupID != "" {
+		// The archived dashboard keeps the charts it owns
+		if err := archiveDashboard(config, url, d); err != nil {
+			return err
+		}
+	} else {
+		if err := resourceDelete(config, url, config.AuthToken, d); err != nil {
+			return err
+		}
+		owned := append(d.Get("copied_chart").([]interface{}), d.Get("inline_chart").([]interface{})...)
+		if err := deleteOwnedCharts(config, owned); err != nil {
+			return err
+		}
+	}
+	if !d.Get("dashboard_group_created").(bool) {
+		return nil
+	}
+	return deleteCreatedDashboardGroup(config, d.Get("dashboard_group").(string))
+}
+
+/*
+  Deletes the group SignalFx created for the dashboard, now empty. This runs after the dashboard is gone,
+  since deleting a group deletes its dashboards too.
+*/
+func deleteCreatedDashboardGroup(config *signalformConfig, groupId string) error {
+	url := fmt.Sprintf("%s/%s", apiUrl(config, DASHBOARD_GROUP_API_PATH), groupId)
+	status_code, resp_body, err := sendRequest(config, "DELETE", url, config.AuthToken, nil)
+	if err != nil {
+		return fmt.Errorf("Failed deleting the dashboard group %s created for the dashboard: %s", groupId, err.Error())
+	}
+	if status_code >= 400 && status_code != 404 {
+		return fmt.Errorf("For the dashboard group %s created for the dashboard SignalFx returned status %d: \n%s", groupId, status_code, resp_body)
+	}
+	return nil
+}
+
+/*
+  Saves the dashboard as it is in SignalFx to the dashboard_backup_dir of the provider, if set, before
+  it's changed. The file (named after the ID and the time) can be sent back to the API, or used as the
+  json of a signalform_dashboard_json resource.
+*/
+func backupDashboard(config *signalformConfig, url string, d *schema.ResourceData) error {
+	if config.DashboardBackupDir == "" || config.DryRun {
+		return nil
+	}
+	dashboard := map[string]interface{}{}
+	if err := getApiResource(config, url, config.AuthToken, &dashboard); err != nil {
+		return fmt.Errorf("Failed reading the resource %s to back it up: %s", d.Get("name"), err.Error())
+	}
+	backup, err := json.MarshalIndent(dashboard, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed creating the backup of the resource %s: %s", d.Get("name"), err.Error())
+	}
+	if err := os.MkdirAll(config.DashboardBackupDir, 0700); err != nil {
+		return fmt.Errorf("Failed creating the backup directory %s: %s", config.DashboardBackupDir, err.Error())
+	}
+	path := filepath.Join(config.DashboardBackupDir, fmt.Sprintf("%s-%d.json", d.Id(), time.Now().Unix()))
+	if err := ioutil.WriteFile(path, backup, 0600); err != nil {
+		return fmt.Errorf("Failed writing the backup of the resource %s: %s", d.Get("name"), err.Error())
+	}
+	log.Printf("[INFO] Saved the dashboard %s to %s", d.Id(), path)
+	return nil
+}
+
+/*
+  Moves the dashboard to the archive dashboard group of the provider instead of deleting it. Its charts
+  are recorded, for the chart resources to leave them in place when they're destroyed next.
+*/
+func archiveDashboard(config *signalformConfig, url string, d *schema.ResourceData) error {
+	if config.Protect {
+		return fmt.Errorf("protect is enabled, refusing to archive the resource %s (%s)", d.Get("name"), d.Id())
+	}
+	dashboard := map[string]interface{}{}
+	if err := getApiResource(config, url, config.AuthToken, &dashboard); err != nil {
+		return fmt.Errorf("Failed reading the resource %s to archive it: %s", d.Get("name"), err.Error())
+	}
+	dashboard["groupId"] = config.ArchiveDashboardGroupID
+	payload, err := json.Marshal(dashboard)
+	if err != nil {
+		return fmt.Errorf("Failed creating json payload: %s", err.Error())
+	}
+	if config.DryRun {
+		logDryRun("PUT", url, payload, d)
+		return nil
+	}
+	status_code, resp_body, err := sendRequest(config, "PUT", url, config.AuthToken, payload)
+	if err != nil {
+		return fmt.Errorf("Failed archiving resource %s: %s", d.Get("name"), err.Error())
+	}
+	if status_code != 200 {
+		return fmt.Errorf("For the resource %s SignalFx returned status %d: \n%s", d.Get("name"), status_code, resp_body)
+	}
+	config.addArchivedCharts(getDashboardChartIdsFromApi(dashboard))
+	d.SetId("")
+	return nil
+}
+
+/*
+  IDs of the charts of a dashboard returned by SignalFx
+*/
+func getDashboardChartIdsFromApi(dashboard map[string]interface{}) []string {
+	charts, _ := dashboard["charts"].([]interface{})
+	ids := make([]string, 0, len(charts))
+	for _, chart := range charts {
+		chart, _ := chart.(map[string]interface{})
+		if id, ok := chart["chartId"].(string); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+/*
+  Tag of the dashboards with an expiration, e.g. expires:2017-07-14T02:40:00Z
+*/
+func expirationTag(expires_at int) string {
+	return "expires:" + time.Unix(int64(expires_at), 0).UTC().Format(time.RFC3339)
+}
+
+/*
+  Seconds since epoch of a tag made by expirationTag, false for any other tag
+*/
+func parseExpirationTag(tag string) (int, bool) {
+	if !strings.HasPrefix(tag, "expires:") {
+		return 0, false
+	}
+	expires, err := time.Parse(time.RFC3339, strings.TrimPrefix(tag, "expires:"))
+	if err != nil {
+		return 0, false
+	}
+	return int(expires.Unix()), true
+}
+
+func isExpired(expires_at int, now time.Time) bool {
+	return expires_at > 0 && now.Unix() >= int64(expires_at)
+}
+
+/*
+  Seconds of an expires_after value, in SignalFx time syntax without the minus (e.g. 12h, 3d)
+*/
+func expiresAfterSeconds(value string) (int, error) {
+	ms, err := fromRangeToMilliSeconds("-" + value)
+	if err != nil {
+		return 0, err
+	}
+	return ms / 1000, nil
+}
+
+func validateExpiresAfter(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	if !regexp.MustCompile("^[0-9]+[mhdw]$").MatchString(value) {
+		errors = append(errors, fmt.Errorf("%s not allowed; %s must be a number of minutes, hours, days or weeks (e.g. 30m, 12h, 3d, 1w)", value, k))
+	}
+	return
 }
 
 /*
@@ -529,3 +1850,84 @@ func validateChartsResolution(v interface{}, k string) (we []string, errors []er
 	errors = append(errors, fmt.Errorf("%s not allowed; must be one of: %s", value, strings.Join(allowedWords, ", ")))
 	return
 }
+
+/*
+  Validates the type of an event overlay
+*/
+func validateEventOverlayType(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	if value != "eventTimeSeries" && value != "detectorEvents" {
+		errors = append(errors, fmt.Errorf("%s not allowed; must be either eventTimeSeries or detectorEvents", value))
+	}
+	return
+}
+
+/*
+  Validates the principal type of a permission
+*/
+func validatePermissionPrincipalType(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	allowedWords := []string{"ORG", "TEAM", "USER"}
+	for _, word := range allowedWords {
+		if value == word {
+			return
+		}
+	}
+	errors = append(errors, fmt.Errorf("%s not allowed; must be one of: %s", value, strings.Join(allowedWords, ", ")))
+	return
+}
+
+/*
+  Validates an action of a permission
+*/
+func validatePermissionAction(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	if value != "READ" && value != "WRITE" {
+		errors = append(errors, fmt.Errorf("%s not allowed; must be either READ or WRITE", value))
+	}
+	return
+}
+
+/*
+  Validates the row of a chart; it must be >= 0
+*/
+func validateChartRow(v interface{}, k string) (we []string, errors []error) {
+	value := v.(int)
+	if value < 0 {
+		errors = append(errors, fmt.Errorf("%d not allowed; %s must be >= 0", value, k))
+	}
+	return
+}
+
+/*
+  Validates the column of a chart; it must be between 0 and 11
+*/
+func validateChartColumn(v interface{}, k string) (we []string, errors []error) {
+	value := v.(int)
+	if value < 0 || value > 11 {
+		errors = append(errors, fmt.Errorf("%d not allowed; %s must be >= 0 && <= 11", value, k))
+	}
+	return
+}
+
+/*
+  Validates the width of a chart; it must be between 1 and 12
+*/
+func validateChartWidth(v interface{}, k string) (we []string, errors []error) {
+	value := v.(int)
+	if value < 1 || value > 12 {
+		errors = append(errors, fmt.Errorf("%d not allowed; %s must be >= 1 && <= 12", value, k))
+	}
+	return
+}
+
+/*
+  Validates the height of a chart; it must be >= 1
+*/
+func validateChartHeight(v interface{}, k string) (we []string, errors []error) {
+	value := v.(int)
+	if value < 1 {
+		errors = append(errors, fmt.Errorf("%d not allowed; %s must be >= 1", value, k))
+	}
+	return
+}
@@ -7,7 +7,7 @@ import (
 	"github.com/hashicorp/terraform/helper/schema"
 )
 
-const DASHBOARD_GROUP_API_URL = "https://api.signalfx.com/v2/dashboardgroup"
+const DASHBOARD_GROUP_API_PATH = "/v2/dashboardgroup"
 
 func dashboardGroupResource() *schema.Resource {
 	return &schema.Resource{
@@ -23,15 +23,23 @@ func dashboardGroupResource() *schema.Resource {
 				Computed:    true,
 				Description: "Latest timestamp the resource was updated",
 			},
+			"drifted_fields": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Fields of the SignalFx object changed outside of Terraform, found when last refreshed",
+			},
+			"credential": credentialSchema(),
 			"name": &schema.Schema{
 				Type:        schema.TypeString,
 				Required:    true,
 				Description: "Name of the dashboard group",
 			},
 			"description": &schema.Schema{
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "Description of the dashboard group",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDescription,
+				Description:  "Description of the dashboard group",
 			},
 			"teams": &schema.Schema{
 				Type:        schema.TypeList,
@@ -71,35 +79,58 @@ func getPayloadDashboardGroup(d *schema.ResourceData) ([]byte, error) {
 }
 
 func dashboardgroupCreate(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
 	payload, err := getPayloadDashboardGroup(d)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
 
-	return resourceCreate(DASHBOARD_GROUP_API_URL, config.AuthToken, payload, d)
+	return resourceCreate(config, apiUrl(config, DASHBOARD_GROUP_API_PATH), config.AuthToken, payload, d)
 }
 
 func dashboardgroupRead(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
-	url := fmt.Sprintf("%s/%s", DASHBOARD_GROUP_API_URL, d.Id())
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, DASHBOARD_GROUP_API_PATH), d.Id())
 
-	return resourceRead(url, config.AuthToken, d)
+	// The state as it would be sent, to tell what changed outside of Terraform
+	payload, err := getPayloadDashboardGroup(d)
+	if err != nil {
+		return fmt.Errorf("Failed creating json payload: %s", err.Error())
+	}
+	// The dashboards are not kept track of, so they never drift
+	group := map[string]interface{}{}
+	json.Unmarshal(payload, &group)
+	delete(group, "dashboards")
+	payload, _ = json.Marshal(group)
+
+	return resourceRead(config, url, config.AuthToken, payload, d)
 }
 
 func dashboardgroupUpdate(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
 	payload, err := getPayloadDashboardGroup(d)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
-	url := fmt.Sprintf("%s/%s", DASHBOARD_GROUP_API_URL, d.Id())
+	url := fmt.Sprintf("%s/%s", apiUrl(config, DASHBOARD_GROUP_API_PATH), d.Id())
 
-	return resourceUpdate(url, config.AuthToken, payload, d)
+	return resourceUpdate(config, url, config.AuthToken, payload, d)
 }
 
 func dashboardgroupDelete(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
-	url := fmt.Sprintf("%s/%s", DASHBOARD_GROUP_API_URL, d.Id())
-	return resourceDelete(url, config.AuthToken, d)
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, DASHBOARD_GROUP_API_PATH), d.Id())
+	return resourceDelete(config, url, config.AuthToken, d)
 }
@@ -0,0 +1,239 @@
+package signalform
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Types of the charts that can be defined inline in a dashboard, as named by the API
+var InlineChartTypes = []string{"TimeSeriesChart", "SingleValue", "List"}
+
+/*
+  Simple charts defined in the dashboard block. The provider creates them and owns them: they are
+  updated with the dashboard, and deleted when removed from it or when the dashboard is deleted.
+*/
+func inlineChartSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Description: "Charts created along with the dashboard, and owned by it",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": &schema.Schema{
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "Name of the chart",
+				},
+				"description": &schema.Schema{
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validateDescription,
+					Description:  "Description of the chart",
+				},
+				"type": &schema.Schema{
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "TimeSeriesChart",
+					ValidateFunc: validateInlineChartType,
+					Description:  "Type of the chart: \"TimeSeriesChart\", \"SingleValue\" or \"List\". \"TimeSeriesChart\" by default",
+				},
+				"program_text": &schema.Schema{
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "Signalflow program text for the chart. More info at \"https://developers.signalfx.com/docs/signalflow-overview\"",
+				},
+				"row": &schema.Schema{
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      -1,
+					ValidateFunc: validateChartRow,
+					Description:  "The row to show the chart in (zero-based), like in the chart blocks",
+				},
+				"column": &schema.Schema{
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      -1,
+					ValidateFunc: validateChartColumn,
+					Description:  "The column to show the chart in (zero-based), like in the chart blocks",
+				},
+				"width": &schema.Schema{
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      12,
+					ValidateFunc: validateChartWidth,
+					Description:  "How many columns (out of a total of 12) the chart should take up. (between 1 and 12)",
+				},
+				"height": &schema.Schema{
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      1,
+					ValidateFunc: validateChartHeight,
+					Description:  "How many rows the chart should take up. (greater than or equal to 1)",
+				},
+				"chart_id": &schema.Schema{
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "ID of the chart created by the provider",
+				},
+			},
+		},
+	}
+}
+
+func getPayloadInlineChart(config *signalformConfig, chart map[string]interface{}) ([]byte, error) {
+	payload := map[string]interface{}{
+		"name":        chart["name"].(string),
+		"description": chart["description"].(string),
+		"programText": sanitizeProgramText(chart["program_text"].(string)),
+		"options": map[string]interface{}{
+			"type": chart["type"].(string),
+		},
+	}
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return addNameAffixes(config, encoded)
+}
+
+/*
+  Layout of the inline charts, for the dashboard payload
+*/
+func getDashboardInlineCharts(d *schema.ResourceData) []map[string]interface{} {
+	charts := d.Get("inline_chart").([]interface{})
+	charts_list := make([]map[string]interface{}, len(charts))
+	for i, chart := range charts {
+		chart := chart.(map[string]interface{})
+		item := make(map[string]interface{})
+
+		item["chartId"] = chart["chart_id"].(string)
+		item["row"] = chart["row"].(int)
+		item["column"] = chart["column"].(int)
+		item["height"] = chart["height"].(int)
+		item["width"] = chart["width"].(int)
+
+		charts_list[i] = item
+	}
+	return charts_list
+}
+
+/*
+  Creates the new inline charts and updates the existing ones, storing their IDs; then deletes the
+  ones removed from the configuration. Meant to run before the dashboard is sent, which needs the IDs.
+*/
+func saveInlineCharts(config *signalformConfig, d *schema.ResourceData) error {
+	if config.DryRun {
+		// The dashboard itself reports what would be sent
+		return nil
+	}
+	old, new := d.GetChange("inline_chart")
+	charts := new.([]interface{})
+	removed := matchInlineCharts(old.([]interface{}), charts)
+	for _, chart := range charts {
+		chart := chart.(map[string]interface{})
+		payload, err := getPayloadInlineChart(config, chart)
+		if err != nil {
+			return fmt.Errorf("Failed creating json payload: %s", err.Error())
+		}
+
+		if chart_id := chart["chart_id"].(string); chart_id != "" {
+			if config.Protect {
+				return fmt.Errorf("protect is enabled, refusing to update the chart %s of the dashboard %s", chart_id, d.Get("name"))
+			}
+			url := fmt.Sprintf("%s/%s", apiUrl(config, CHART_API_PATH), chart_id)
+			if _, err := sendInlineChart(config, "PUT", url, payload); err != nil {
+				return err
+			}
+			continue
+		}
+		chart_id, err := sendInlineChart(config, "POST", apiUrl(config, CHART_API_PATH), payload)
+		if err != nil {
+			// Keep the charts created so far
+			d.Set("inline_chart", charts)
+			return err
+		}
+		chart["chart_id"] = chart_id
+	}
+	d.Set("inline_chart", charts)
+
+	if len(removed) > 0 && config.Protect {
+		return fmt.Errorf("protect is enabled, refusing to delete the charts removed from the dashboard %s", d.Get("name"))
+	}
+	return deleteOwnedCharts(config, removed)
+}
+
+/*
+  Gives the inline charts of the configuration the ID of the chart of the same name in the state, and
+  returns the charts of the state left without a block. The blocks are matched by name rather than
+  by position: the chart_id of a block comes from the state at its index, so removing a block would
+  otherwise send every chart after it to the chart of the previous block. Charts of the same name are
+  matched in order; a renamed chart is a new chart.
+*/
+func matchInlineCharts(old []interface{}, new []interface{}) []interface{} {
+	by_name := make(map[string][]map[string]interface{})
+	for _, chart := range old {
+		chart := chart.(map[string]interface{})
+		if chart_id, _ := chart["chart_id"].(string); chart_id != "" {
+			name := chart["name"].(string)
+			by_name[name] = append(by_name[name], chart)
+		}
+	}
+	for _, chart := range new {
+		chart := chart.(map[string]interface{})
+		chart["chart_id"] = ""
+		if existing := by_name[chart["name"].(string)]; len(existing) > 0 {
+			chart["chart_id"] = existing[0]["chart_id"]
+			by_name[chart["name"].(string)] = existing[1:]
+		}
+	}
+	removed := make([]interface{}, 0)
+	for _, chart := range old {
+		chart := chart.(map[string]interface{})
+		for _, left := range by_name[chart["name"].(string)] {
+			if left["chart_id"] == chart["chart_id"] {
+				removed = append(removed, chart)
+			}
+		}
+	}
+	return removed
+}
+
+/*
+  Sends an inline chart, returning its ID
+*/
+func sendInlineChart(config *signalformConfig, method string, url string, payload []byte) (string, error) {
+	status_code, resp_body, err := sendRequest(config, method, url, config.AuthToken, payload)
+	if err != nil {
+		return "", fmt.Errorf("Failed sending the inline chart: %s", err.Error())
+	}
+	if status_code != 200 {
+		return "", fmt.Errorf("For the inline chart SignalFx returned status %d: \n%s", status_code, resp_body)
+	}
+	chart := map[string]interface{}{}
+	if err := json.Unmarshal(resp_body, &chart); err != nil {
+		return "", fmt.Errorf("Failed unmarshaling the inline chart: %s", err.Error())
+	}
+	// Without its ID, the chart would be created again by the next update, and never deleted
+	chart_id, _ := chart["id"].(string)
+	if chart_id == "" {
+		return "", fmt.Errorf("SignalFx returned no ID for the inline chart: \n%s", resp_body)
+	}
+	return chart_id, nil
+}
+
+/*
+  Validates the type of an inline chart
+*/
+func validateInlineChartType(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	for _, chartType := range InlineChartTypes {
+		if value == chartType {
+			return
+		}
+	}
+	errors = append(errors, fmt.Errorf("%s not allowed; must be one of: %s", value, strings.Join(InlineChartTypes, ", ")))
+	return
+}
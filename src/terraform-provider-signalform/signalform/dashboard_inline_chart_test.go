@@ -0,0 +1,74 @@
+package signalform
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidateInlineChartType(t *testing.T) {
+	for _, value := range []string{"TimeSeriesChart", "SingleValue", "List"} {
+		_, errors := validateInlineChartType(value, "type")
+		assert.Equal(t, 0, len(errors))
+	}
+	_, errors := validateInlineChartType("Heatmap", "type")
+	assert.Equal(t, 1, len(errors))
+}
+
+func TestGetPayloadInlineChart(t *testing.T) {
+	chart := map[string]interface{}{
+		"name":         "Latency",
+		"description":  "",
+		"type":         "SingleValue",
+		"program_text": "data('latency').publish()",
+	}
+	payload, err := getPayloadInlineChart(&signalformConfig{NamePrefix: "[tf] "}, chart)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"description":"","name":"[tf] Latency","options":{"type":"SingleValue"},"programText":"data('latency').publish()"}`, string(payload))
+}
+
+func TestMatchInlineCharts(t *testing.T) {
+	old := []interface{}{
+		map[string]interface{}{"name": "Requests", "chart_id": "CREQUESTS"},
+		map[string]interface{}{"name": "Errors", "chart_id": "CERRORS"},
+		map[string]interface{}{"name": "Latency", "chart_id": "CLATENCY"},
+	}
+	// Errors removed from the middle: the chart_id of the blocks come from the state at their index
+	new := []interface{}{
+		map[string]interface{}{"name": "Requests", "chart_id": "CREQUESTS"},
+		map[string]interface{}{"name": "Latency", "chart_id": "CERRORS"},
+		map[string]interface{}{"name": "Saturation", "chart_id": "CLATENCY"},
+	}
+
+	removed := matchInlineCharts(old, new)
+	assert.Equal(t, "CREQUESTS", new[0].(map[string]interface{})["chart_id"])
+	assert.Equal(t, "CLATENCY", new[1].(map[string]interface{})["chart_id"])
+	assert.Equal(t, "", new[2].(map[string]interface{})["chart_id"])
+	assert.Equal(t, []interface{}{old[1]}, removed)
+}
+
+func TestMatchInlineChartsSameName(t *testing.T) {
+	old := []interface{}{
+		map[string]interface{}{"name": "CPU", "chart_id": "CFIRST"},
+		map[string]interface{}{"name": "CPU", "chart_id": "CSECOND"},
+	}
+	new := []interface{}{
+		map[string]interface{}{"name": "CPU", "chart_id": "CFIRST"},
+	}
+
+	removed := matchInlineCharts(old, new)
+	assert.Equal(t, "CFIRST", new[0].(map[string]interface{})["chart_id"])
+	assert.Equal(t, []interface{}{old[1]}, removed)
+}
+
+func TestSendInlineChartWithoutId(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"Latency"}`)
+	}))
+	defer server.Close()
+
+	_, err := sendInlineChart(&signalformConfig{}, "POST", server.URL+"/v2/chart", []byte(`{}`))
+	assert.Contains(t, err.Error(), "SignalFx returned no ID for the inline chart")
+}
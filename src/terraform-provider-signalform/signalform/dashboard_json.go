@@ -0,0 +1,233 @@
+package signalform
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dashboardJsonResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"synced": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether the resource in SignalForm and SignalFx are identical or not. Used internally for syncing.",
+			},
+			"last_updated": &schema.Schema{
+				Type:        schema.TypeFloat,
+				Computed:    true,
+				Description: "Latest timestamp the resource was updated",
+			},
+			"drifted_fields": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Fields of the SignalFx object changed outside of Terraform, found when last refreshed",
+			},
+			"resource_url": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     DASHBOARD_URL,
+				Description: "API URL of the dashboard",
+			},
+			"url": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "URL of the dashboard",
+			},
+			"json": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateDashboardJson,
+				StateFunc:    normalizeDashboardJsonState,
+				Description:  "JSON document of the dashboard, as accepted by the API",
+			},
+			"dashboard_group": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The ID of the dashboard group that contains the dashboard, overriding the groupId of the document. Defaults to the default_dashboard_group_id of the provider when the document has none",
+			},
+			"credential": credentialSchema(),
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Name of the dashboard, from the document",
+			},
+		},
+
+		Create: dashboardJsonCreate,
+		Read:   dashboardJsonRead,
+		Update: dashboardJsonUpdate,
+		Delete: dashboardJsonDelete,
+	}
+}
+
+/*
+  Decodes the document and removes the fields set by SignalFx
+*/
+func getDashboardJsonDocument(document string) (map[string]interface{}, error) {
+	dashboard := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(document), &dashboard); err != nil {
+		return nil, err
+	}
+	for _, field := range ApiReadOnlyFields {
+		delete(dashboard, field)
+	}
+	return dashboard, nil
+}
+
+/*
+  Normalized form of the document: without the fields set by SignalFx, compact and with sorted keys,
+  so formatting changes in the configuration don't show up in the plan
+*/
+func normalizeDashboardJson(document string) (string, error) {
+	dashboard, err := getDashboardJsonDocument(document)
+	if err != nil {
+		return "", err
+	}
+	normalized, err := json.Marshal(dashboard)
+	if err != nil {
+		return "", err
+	}
+	return string(normalized), nil
+}
+
+func normalizeDashboardJsonState(v interface{}) string {
+	normalized, err := normalizeDashboardJson(v.(string))
+	if err != nil {
+		// Already rejected by validateDashboardJson
+		return v.(string)
+	}
+	return normalized
+}
+
+func getPayloadDashboardJson(d *schema.ResourceData, config *signalformConfig) ([]byte, error) {
+	dashboard, err := getDashboardJsonDocument(d.Get("json").(string))
+	if err != nil {
+		return nil, err
+	}
+	if groupId := d.Get("dashboard_group").(string); groupId != "" {
+		dashboard["groupId"] = groupId
+	} else if _, ok := dashboard["groupId"]; !ok && config.DefaultDashboardGroupID != "" {
+		dashboard["groupId"] = config.DefaultDashboardGroupID
+	}
+	name, _ := dashboard["name"].(string)
+	d.Set("name", name)
+
+	return json.Marshal(dashboard)
+}
+
+func dashboardJsonCreate(d *schema.ResourceData, meta interface{}) error {
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
+	payload, err := getPayloadDashboardJson(d, config)
+	if err != nil {
+		return fmt.Errorf("Failed creating json payload: %s", err.Error())
+	}
+
+	return resourceCreate(config, apiUrl(config, DASHBOARD_API_PATH), config.AuthToken, payload, d)
+}
+
+func dashboardJsonRead(d *schema.ResourceData, meta interface{}) error {
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, DASHBOARD_API_PATH), d.Id())
+
+	// The state as it would be sent, to tell what changed outside of Terraform
+	payload, err := getPayloadDashboardJson(d, config)
+	if err != nil {
+		return fmt.Errorf("Failed creating json payload: %s", err.Error())
+	}
+
+	return resourceReadFields(config, url, config.AuthToken, payload, d, func(dashboard map[string]interface{}) error {
+		document, err := getDashboardJsonFromApi(config, dashboard, d.Get("json").(string), d.Get("dashboard_group").(string) != "")
+		if err != nil {
+			return err
+		}
+		d.Set("json", document)
+		return nil
+	})
+}
+
+/*
+  Document of the dashboard returned by SignalFx, limited to the fields of the configured document: the
+  API fills in many defaults, which would otherwise show up as changes in every plan. When dashboard_group
+  overrides the groupId of the document, that groupId is kept as configured: the group of the dashboard
+  is still compared, through dashboard_group in the payload.
+*/
+func getDashboardJsonFromApi(config *signalformConfig, dashboard map[string]interface{}, configured string, groupOverridden bool) (string, error) {
+	configured_dashboard, err := getDashboardJsonDocument(configured)
+	if err != nil {
+		return "", err
+	}
+	document := make(map[string]interface{})
+	for field, configured_value := range configured_dashboard {
+		if field == "groupId" && groupOverridden {
+			document[field] = configured_value
+		} else if value, ok := dashboard[field]; ok {
+			document[field] = value
+		}
+	}
+	if name, ok := document["name"].(string); ok {
+		document["name"] = removeNameAffixes(config, name)
+	}
+	normalized, err := json.Marshal(document)
+	if err != nil {
+		return "", err
+	}
+	return string(normalized), nil
+}
+
+func dashboardJsonUpdate(d *schema.ResourceData, meta interface{}) error {
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
+	payload, err := getPayloadDashboardJson(d, config)
+	if err != nil {
+		return fmt.Errorf("Failed creating json payload: %s", err.Error())
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, DASHBOARD_API_PATH), d.Id())
+	if err := backupDashboard(config, url, d); err != nil {
+		return err
+	}
+
+	return resourceUpdate(config, url, config.AuthToken, payload, d)
+}
+
+func dashboardJsonDelete(d *schema.ResourceData, meta interface{}) error {
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, DASHBOARD_API_PATH), d.Id())
+	if err := backupDashboard(config, url, d); err != nil {
+		return err
+	}
+	if config.ArchiveDashboardGroupID != "" {
+		return archiveDashboard(config, url, d)
+	}
+	return resourceDelete(config, url, config.AuthToken, d)
+}
+
+/*
+  Validates the document of a dashboard; it must be a JSON object with a name
+*/
+func validateDashboardJson(v interface{}, k string) (we []string, errors []error) {
+	dashboard, err := getDashboardJsonDocument(v.(string))
+	if err != nil {
+		errors = append(errors, fmt.Errorf("%s is not a valid JSON object: %s", k, err.Error()))
+		return
+	}
+	if name, ok := dashboard["name"].(string); !ok || name == "" {
+		errors = append(errors, fmt.Errorf("%s must have a name", k))
+	}
+	return
+}
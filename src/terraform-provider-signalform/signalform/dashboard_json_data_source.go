@@ -0,0 +1,47 @@
+package signalform
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dashboardJsonDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"dashboard_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "ID of the dashboard",
+			},
+			"json": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "JSON document of the dashboard as returned by the API, with sorted keys",
+			},
+		},
+
+		Read: dashboardJsonDataSourceRead,
+	}
+}
+
+func dashboardJsonDataSourceRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	dashboardId := d.Get("dashboard_id").(string)
+
+	dashboard := map[string]interface{}{}
+	if err := getApiResource(config, fmt.Sprintf("%s/%s", apiUrl(config, DASHBOARD_API_PATH), dashboardId), config.AuthToken, &dashboard); err != nil {
+		return fmt.Errorf("Failed reading the dashboard %s: %s", dashboardId, err.Error())
+	}
+	// Marshaling a map sorts the keys, so the document is stable across reads
+	document, err := json.MarshalIndent(dashboard, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed marshaling the dashboard %s: %s", dashboardId, err.Error())
+	}
+
+	d.SetId(dashboardId)
+	d.Set("json", string(document))
+
+	return nil
+}
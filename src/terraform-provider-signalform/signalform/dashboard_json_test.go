@@ -0,0 +1,62 @@
+package signalform
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNormalizeDashboardJson(t *testing.T) {
+	normalized, err := normalizeDashboardJson(`{
+		"name": "My Dashboard",
+		"id": "DXYZ",
+		"lastUpdated": 1500000000000,
+		"charts": []
+	}`)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"charts":[],"name":"My Dashboard"}`, normalized)
+}
+
+func TestNormalizeDashboardJsonInvalid(t *testing.T) {
+	_, err := normalizeDashboardJson("not json")
+	assert.NotNil(t, err)
+}
+
+func TestGetDashboardJsonFromApi(t *testing.T) {
+	config := &signalformConfig{NamePrefix: "[tf] "}
+	dashboard := map[string]interface{}{
+		"id":           "DXYZ",
+		"name":         "[tf] My Dashboard",
+		"description":  "Changed in the UI",
+		"chartDensity": "DEFAULT",
+	}
+	document, err := getDashboardJsonFromApi(config, dashboard, `{"name": "My Dashboard", "description": "Mine"}`, false)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"description":"Changed in the UI","name":"My Dashboard"}`, document)
+}
+
+func TestGetDashboardJsonFromApiGroupOverridden(t *testing.T) {
+	dashboard := map[string]interface{}{
+		"id":      "DXYZ",
+		"name":    "My Dashboard",
+		"groupId": "GOVERRIDE",
+	}
+	configured := `{"name": "My Dashboard", "groupId": "GEXPORTED"}`
+
+	// The groupId of the document is replaced by dashboard_group when sent
+	document, err := getDashboardJsonFromApi(&signalformConfig{}, dashboard, configured, true)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"groupId":"GEXPORTED","name":"My Dashboard"}`, document)
+
+	document, err = getDashboardJsonFromApi(&signalformConfig{}, dashboard, configured, false)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"groupId":"GOVERRIDE","name":"My Dashboard"}`, document)
+}
+
+func TestValidateDashboardJson(t *testing.T) {
+	_, errors := validateDashboardJson(`{"name": "My Dashboard"}`, "json")
+	assert.Equal(t, 0, len(errors))
+	_, errors = validateDashboardJson(`{"charts": []}`, "json")
+	assert.Equal(t, 1, len(errors))
+	_, errors = validateDashboardJson(`[]`, "json")
+	assert.Equal(t, 1, len(errors))
+}
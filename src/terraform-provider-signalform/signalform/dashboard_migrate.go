@@ -0,0 +1,37 @@
+package signalform
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func dashboardMigrateState(version int, state *terraform.InstanceState, meta interface{}) (*terraform.InstanceState, error) {
+	switch version {
+	case 0:
+		log.Printf("[INFO] Migrating the state of the dashboard %s from version 0 to 1", state.ID)
+		return migrateDashboardStateV0toV1(state), nil
+	default:
+		return state, fmt.Errorf("Unexpected schema version of the dashboard %s: %d", state.ID, version)
+	}
+}
+
+/*
+  The row and column of the chart blocks used to default to 0, and now to -1 (not set, for auto_arrange).
+  The 0 positions of the older states are taken for unset ones, which they are for the configurations
+  that omit them; an explicit 0 shows as a change once, which sends the same dashboard.
+*/
+func migrateDashboardStateV0toV1(state *terraform.InstanceState) *terraform.InstanceState {
+	if state == nil || state.Attributes == nil {
+		return state
+	}
+	position := regexp.MustCompile(`^chart\.[0-9]+\.(row|column)$`)
+	for key, value := range state.Attributes {
+		if position.MatchString(key) && value == "0" {
+			state.Attributes[key] = "-1"
+		}
+	}
+	return state
+}
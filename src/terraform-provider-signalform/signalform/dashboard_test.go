@@ -1,8 +1,20 @@
 package signalform
 
 import (
-	"github.com/stretchr/testify/assert"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestValidateChartsResolutionAllowed(t *testing.T) {
@@ -16,3 +28,471 @@ func TestValidateChartsResolutionNotAllowed(t *testing.T) {
 	_, errors := validateChartsResolution("whatever", "charts_resolution")
 	assert.Equal(t, len(errors), 1)
 }
+
+func TestValidateEventOverlayTypeAllowed(t *testing.T) {
+	for _, value := range []string{"eventTimeSeries", "detectorEvents"} {
+		_, errors := validateEventOverlayType(value, "type")
+		assert.Equal(t, len(errors), 0)
+	}
+}
+
+func TestValidateEventOverlayTypeNotAllowed(t *testing.T) {
+	_, errors := validateEventOverlayType("whatever", "type")
+	assert.Equal(t, len(errors), 1)
+}
+
+func TestValidatePermissionPrincipalTypeAllowed(t *testing.T) {
+	for _, value := range []string{"ORG", "TEAM", "USER"} {
+		_, errors := validatePermissionPrincipalType(value, "principal_type")
+		assert.Equal(t, len(errors), 0)
+	}
+}
+
+func TestValidatePermissionPrincipalTypeNotAllowed(t *testing.T) {
+	_, errors := validatePermissionPrincipalType("team", "principal_type")
+	assert.Equal(t, len(errors), 1)
+}
+
+func TestValidatePermissionActionAllowed(t *testing.T) {
+	for _, value := range []string{"READ", "WRITE"} {
+		_, errors := validatePermissionAction(value, "actions")
+		assert.Equal(t, len(errors), 0)
+	}
+}
+
+func TestValidatePermissionActionNotAllowed(t *testing.T) {
+	_, errors := validatePermissionAction("DELETE", "actions")
+	assert.Equal(t, len(errors), 1)
+}
+
+func TestGetGridCharts(t *testing.T) {
+	charts := getGridCharts([]interface{}{"A", "B", "C"}, 1, 0, 6, 2)
+	assert.Equal(t, 3, len(charts))
+	assert.Equal(t, map[string]interface{}{"chartId": "A", "row": 1, "column": 0, "width": 6, "height": 2}, charts[0])
+	assert.Equal(t, map[string]interface{}{"chartId": "B", "row": 1, "column": 6, "width": 6, "height": 2}, charts[1])
+	// Next row starts below the charts of the previous one
+	assert.Equal(t, map[string]interface{}{"chartId": "C", "row": 3, "column": 0, "width": 6, "height": 2}, charts[2])
+}
+
+func TestGetGridChartsStartColumn(t *testing.T) {
+	charts := getGridCharts([]interface{}{"A", "B"}, 0, 4, 4, 1)
+	assert.Equal(t, 0, charts[0]["row"])
+	assert.Equal(t, 4, charts[0]["column"])
+	assert.Equal(t, 0, charts[1]["row"])
+	assert.Equal(t, 8, charts[1]["column"])
+}
+
+func TestGetColumnCharts(t *testing.T) {
+	charts := getColumnCharts([]interface{}{"A", "B"}, 1, 6, 4, 2)
+	assert.Equal(t, 2, len(charts))
+	assert.Equal(t, map[string]interface{}{"chartId": "A", "row": 1, "column": 6, "width": 4, "height": 2}, charts[0])
+	assert.Equal(t, map[string]interface{}{"chartId": "B", "row": 3, "column": 6, "width": 4, "height": 2}, charts[1])
+}
+
+func TestArrangeCharts(t *testing.T) {
+	charts := []map[string]interface{}{
+		{"chartId": "A", "row": 0, "column": 0, "width": 6, "height": 2},
+		{"chartId": "B", "row": -1, "column": -1, "width": 6, "height": 1},
+		{"chartId": "C", "row": -1, "column": -1, "width": 6, "height": 1},
+		{"chartId": "D", "row": -1, "column": -1, "width": 12, "height": 1},
+		{"chartId": "E", "row": -1, "column": 0, "width": 3, "height": 1},
+		{"chartId": "F", "row": 1, "column": -1, "width": 6, "height": 1},
+	}
+	arrangeCharts(charts)
+
+	positions := map[string][2]int{}
+	for _, chart := range charts {
+		positions[chart["chartId"].(string)] = [2]int{chart["row"].(int), chart["column"].(int)}
+	}
+	assert.Equal(t, [2]int{0, 0}, positions["A"])
+	assert.Equal(t, [2]int{0, 6}, positions["B"])
+	assert.Equal(t, [2]int{1, 6}, positions["C"])
+	assert.Equal(t, [2]int{2, 0}, positions["D"])
+	assert.Equal(t, [2]int{3, 0}, positions["E"])
+	assert.Equal(t, [2]int{3, 3}, positions["F"])
+}
+
+func TestValidateChartRow(t *testing.T) {
+	_, errors := validateChartRow(0, "row")
+	assert.Equal(t, 0, len(errors))
+	_, errors = validateChartRow(-1, "row")
+	assert.Equal(t, 1, len(errors))
+}
+
+func TestValidateChartColumn(t *testing.T) {
+	for _, value := range []int{0, 11} {
+		_, errors := validateChartColumn(value, "column")
+		assert.Equal(t, 0, len(errors))
+	}
+	for _, value := range []int{-1, 12} {
+		_, errors := validateChartColumn(value, "column")
+		assert.Equal(t, 1, len(errors))
+	}
+}
+
+func TestValidateChartWidth(t *testing.T) {
+	for _, value := range []int{1, 12} {
+		_, errors := validateChartWidth(value, "width")
+		assert.Equal(t, 0, len(errors))
+	}
+	for _, value := range []int{0, 13} {
+		_, errors := validateChartWidth(value, "width")
+		assert.Equal(t, 1, len(errors))
+	}
+}
+
+func TestValidateChartHeight(t *testing.T) {
+	_, errors := validateChartHeight(1, "height")
+	assert.Equal(t, 0, len(errors))
+	_, errors = validateChartHeight(0, "height")
+	assert.Equal(t, 1, len(errors))
+}
+
+func TestCheckOverlappingCharts(t *testing.T) {
+	charts := []map[string]interface{}{
+		{"chartId": "A", "row": 0, "column": 0, "width": 6, "height": 2},
+		{"chartId": "B", "row": 0, "column": 6, "width": 6, "height": 1},
+		{"chartId": "C", "row": 1, "column": 6, "width": 6, "height": 1},
+		// Placed by SignalFx
+		{"chartId": "D", "row": -1, "column": -1, "width": 12, "height": 1},
+		{"chartId": "E", "row": -1, "column": -1, "width": 12, "height": 1},
+	}
+	assert.Nil(t, checkOverlappingCharts(charts))
+}
+
+func TestCheckOverlappingChartsCollide(t *testing.T) {
+	charts := []map[string]interface{}{
+		{"chartId": "A", "row": 0, "column": 0, "width": 6, "height": 2},
+		{"chartId": "B", "row": 1, "column": 4, "width": 4, "height": 1},
+	}
+	err := checkOverlappingCharts(charts)
+	assert.NotNil(t, err)
+	assert.Equal(t, "Overlapping charts in the dashboard: A and B (row 1, column 4)", err.Error())
+}
+
+func TestGetDashboardFiltersFromApi(t *testing.T) {
+	filters := getDashboardFiltersFromApi([]interface{}{
+		map[string]interface{}{"property": "region", "NOT": true, "value": []interface{}{"us-west-1"}, "applyIfExists": true},
+	})
+	assert.Equal(t, 1, len(filters))
+	filter := filters[0].(map[string]interface{})
+	assert.Equal(t, "region", filter["property"])
+	assert.Equal(t, true, filter["negated"])
+	assert.Equal(t, []interface{}{"us-west-1"}, filter["values"].(*schema.Set).List())
+	assert.Equal(t, true, filter["apply_if_exists"])
+}
+
+func TestGetDashboardVariablesFromApi(t *testing.T) {
+	variables := getDashboardVariablesFromApi([]interface{}{
+		map[string]interface{}{"property": "env", "alias": "Environment", "value": "", "required": true, "preferredSuggestions": []interface{}{"prod"}},
+	})
+	assert.Equal(t, 1, len(variables))
+	variable := variables[0].(map[string]interface{})
+	assert.Equal(t, "env", variable["property"])
+	assert.Equal(t, "Environment", variable["alias"])
+	assert.Equal(t, "", variable["description"])
+	assert.Equal(t, 0, variable["values"].(*schema.Set).Len())
+	assert.Equal(t, true, variable["value_required"])
+	assert.Equal(t, []interface{}{"prod"}, variable["values_suggested"].(*schema.Set).List())
+	assert.Equal(t, false, variable["restricted_suggestions"])
+}
+
+func TestGetVariableValueLinks(t *testing.T) {
+	links, err := getVariableValueLinks([]interface{}{
+		map[string]interface{}{"label": "Host dashboard", "dashboard_id": "DHOST", "url": ""},
+		map[string]interface{}{"label": "Runbook", "dashboard_id": "", "url": "https://wiki/{{value}}"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []map[string]interface{}{
+		{"name": "Host dashboard", "type": "SignalFxDashboard", "dashboardId": "DHOST"},
+		{"name": "Runbook", "type": "ExternalUrl", "url": "https://wiki/{{value}}"},
+	}, links)
+
+	_, err = getVariableValueLinks([]interface{}{map[string]interface{}{"label": "Both", "dashboard_id": "DHOST", "url": "https://wiki"}})
+	assert.NotNil(t, err)
+	_, err = getVariableValueLinks([]interface{}{map[string]interface{}{"label": "None", "dashboard_id": "", "url": ""}})
+	assert.NotNil(t, err)
+}
+
+func TestGetVariableValueLinksFromApi(t *testing.T) {
+	links := getVariableValueLinksFromApi([]interface{}{
+		map[string]interface{}{"name": "Host dashboard", "type": "SignalFxDashboard", "dashboardId": "DHOST"},
+	})
+	assert.Equal(t, []interface{}{map[string]interface{}{"label": "Host dashboard", "dashboard_id": "DHOST", "url": ""}}, links)
+	assert.Equal(t, []interface{}{}, getVariableValueLinksFromApi(nil))
+}
+
+func TestGetDashboardTimeFromApi(t *testing.T) {
+	time_range, start_time, end_time := getDashboardTimeFromApi(map[string]interface{}{"start": "-1h", "end": "Now"})
+	assert.Equal(t, "-1h", time_range)
+	assert.Equal(t, 0, start_time)
+	assert.Equal(t, 0, end_time)
+
+	time_range, start_time, end_time = getDashboardTimeFromApi(map[string]interface{}{"start": float64(1500000000000), "end": float64(1500003600000)})
+	assert.Equal(t, "", time_range)
+	assert.Equal(t, 1500000000, start_time)
+	assert.Equal(t, 1500003600, end_time)
+
+	time_range, start_time, end_time = getDashboardTimeFromApi(nil)
+	assert.Equal(t, "", time_range)
+	assert.Equal(t, 0, start_time)
+}
+
+func TestGetDashboardChartsFromApi(t *testing.T) {
+	api_charts := []interface{}{
+		map[string]interface{}{"chartId": "A", "row": float64(1), "column": float64(6), "width": float64(6), "height": float64(2)},
+		map[string]interface{}{"chartId": "B", "row": float64(0), "column": float64(0), "width": float64(6), "height": float64(1), "ignoreFilters": true},
+	}
+	tf_charts := []interface{}{
+		map[string]interface{}{"chart_id": "A", "row": 1, "column": 0, "width": 6, "height": 1},
+		map[string]interface{}{"chart_id": "B", "row": -1, "column": -1, "width": 6, "height": 1},
+	}
+	charts := getDashboardChartsFromApi(api_charts, tf_charts)
+	assert.Equal(t, map[string]interface{}{"chart_id": "A", "row": 1, "column": 6, "width": 6, "height": 2, "ignore_filters": false}, charts[0])
+	assert.Equal(t, map[string]interface{}{"chart_id": "B", "row": -1, "column": -1, "width": 6, "height": 1, "ignore_filters": true}, charts[1])
+}
+
+func TestCheckChartsExist(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/v2/chart/typo") {
+			w.WriteHeader(404)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+	config := &signalformConfig{APIURL: server.URL}
+
+	assert.Nil(t, checkChartsExist(config, []string{"A", "B"}))
+	err := checkChartsExist(config, []string{"A", "typo"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Charts not found in SignalFx: typo")
+}
+
+func TestCheckChartsExistError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(401)
+	}))
+	defer server.Close()
+
+	err := checkChartsExist(&signalformConfig{APIURL: server.URL}, []string{"A"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "returned status 401")
+}
+
+func TestDeleteCreatedDashboardGroup(t *testing.T) {
+	deleted := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			deleted = r.URL.Path
+		}
+	}))
+	defer server.Close()
+
+	assert.Nil(t, deleteCreatedDashboardGroup(&signalformConfig{APIURL: server.URL}, "GXYZ"))
+	assert.Equal(t, "/v2/dashboardgroup/GXYZ", deleted)
+}
+
+func TestDeleteCreatedDashboardGroupError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(403)
+	}))
+	defer server.Close()
+
+	err := deleteCreatedDashboardGroup(&signalformConfig{APIURL: server.URL}, "GXYZ")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "returned status 403")
+}
+
+func TestCopyDashboardCharts(t *testing.T) {
+	posted := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/dashboard/DSOURCE":
+			fmt.Fprint(w, `{"id": "DSOURCE", "charts": [{"chartId": "CA", "row": 0, "column": 6, "width": 6, "height": 2}]}`)
+		case r.URL.Path == "/v2/chart/CA":
+			fmt.Fprint(w, `{"id": "CA", "name": "Latency", "creator": "someone", "programText": "data('latency').publish()"}`)
+		case r.Method == "POST" && r.URL.Path == "/v2/chart":
+			body, _ := ioutil.ReadAll(r.Body)
+			posted = append(posted, string(body))
+			fmt.Fprint(w, `{"id": "CCOPY"}`)
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	copied, err := copyDashboardCharts(&signalformConfig{APIURL: server.URL}, "DSOURCE")
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{map[string]interface{}{"chart_id": "CCOPY", "row": 0, "column": 6, "width": 6, "height": 2}}, copied)
+	// The fields set by SignalFx aren't sent
+	assert.Equal(t, []string{`{"name":"Latency","programText":"data('latency').publish()"}`}, posted)
+}
+
+func TestDeleteOwnedCharts(t *testing.T) {
+	deleted := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deleted = append(deleted, r.Method+" "+r.URL.Path)
+	}))
+	defer server.Close()
+	copied := []interface{}{map[string]interface{}{"chart_id": "CCOPY"}}
+
+	assert.Nil(t, deleteOwnedCharts(&signalformConfig{APIURL: server.URL}, copied))
+	assert.Equal(t, []string{"DELETE /v2/chart/CCOPY"}, deleted)
+
+	// Even with the archive set, as charts are removed from the dashboards updated in place
+	deleted = []string{}
+	assert.Nil(t, deleteOwnedCharts(&signalformConfig{APIURL: server.URL, ArchiveDashboardGroupID: "GARCHIVE"}, copied))
+	assert.Equal(t, []string{"DELETE /v2/chart/CCOPY"}, deleted)
+}
+
+func TestArchiveDashboard(t *testing.T) {
+	methods := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		fmt.Fprint(w, `{"id":"DASHID","groupId":"GROUPID","charts":[{"chartId":"CHARTID","row":0,"column":0}]}`)
+	}))
+	defer server.Close()
+	config := &signalformConfig{APIURL: server.URL, ArchiveDashboardGroupID: "GARCHIVE"}
+
+	d := dashboardResource().TestResourceData()
+	d.SetId("DASHID")
+	assert.Nil(t, archiveDashboard(config, server.URL+"/v2/dashboard/DASHID", d))
+	assert.Equal(t, []string{"GET", "PUT"}, methods)
+	assert.Equal(t, "", d.Id())
+	assert.True(t, config.isChartArchived("CHARTID"))
+	assert.False(t, config.isChartArchived("OTHERID"))
+}
+
+func TestDashboardGroupUpdatedInPlace(t *testing.T) {
+	// The API moves the dashboard when its groupId changes
+	assert.False(t, dashboardResource().Schema["dashboard_group"].ForceNew)
+	assert.False(t, dashboardJsonResource().Schema["dashboard_group"].ForceNew)
+}
+
+func TestBackupDashboard(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"DASHID","name":"My dashboard"}`)
+	}))
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "backups")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	d := schema.TestResourceDataRaw(t, dashboardResource().Schema, map[string]interface{}{"name": "My dashboard"})
+	d.SetId("DASHID")
+	assert.Nil(t, backupDashboard(&signalformConfig{DashboardBackupDir: dir}, server.URL+"/v2/dashboard/DASHID", d))
+	files, err := filepath.Glob(filepath.Join(dir, "DASHID-*.json"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(files))
+	backup, err := ioutil.ReadFile(files[0])
+	assert.Nil(t, err)
+	assert.Contains(t, string(backup), `"name": "My dashboard"`)
+
+	// Nothing to do without a directory
+	assert.Nil(t, backupDashboard(&signalformConfig{}, "http://unreachable", d))
+}
+
+func TestExpiration(t *testing.T) {
+	ttl, err := expiresAfterSeconds("3d")
+	assert.Nil(t, err)
+	assert.Equal(t, 3*24*3600, ttl)
+	assert.Equal(t, "expires:2017-07-14T02:40:00Z", expirationTag(1500000000))
+	expires_at, ok := parseExpirationTag(expirationTag(1500000000))
+	assert.True(t, ok)
+	assert.Equal(t, 1500000000, expires_at)
+	_, ok = parseExpirationTag("expires:soon")
+	assert.False(t, ok)
+	_, ok = parseExpirationTag("team:sre")
+	assert.False(t, ok)
+
+	assert.False(t, isExpired(0, time.Unix(1500000000, 0)))
+	assert.False(t, isExpired(1500000000, time.Unix(1499999999, 0)))
+	assert.True(t, isExpired(1500000000, time.Unix(1500000000, 0)))
+}
+
+func TestValidateExpiresAfter(t *testing.T) {
+	_, errors := validateExpiresAfter("12h", "expires_after")
+	assert.Equal(t, 0, len(errors))
+	_, errors = validateExpiresAfter("-12h", "expires_after")
+	assert.Equal(t, 1, len(errors))
+	_, errors = validateExpiresAfter("12 hours", "expires_after")
+	assert.Equal(t, 1, len(errors))
+}
+
+func TestResourceDashboardChartHash(t *testing.T) {
+	chart := map[string]interface{}{"chart_id": "A", "row": 0, "column": 0, "width": 6, "height": 1}
+	moved := map[string]interface{}{"chart_id": "A", "row": 2, "column": 6, "width": 6, "height": 2}
+	assert.Equal(t, resourceDashboardChartHash(chart), resourceDashboardChartHash(moved))
+	assert.NotEqual(t, resourceDashboardChartHash(chart), resourceDashboardChartHash(map[string]interface{}{"chart_id": "B"}))
+}
+
+func TestResourceDashboardChartHashUnknownIds(t *testing.T) {
+	// Charts created in the same apply, whose IDs are only known once they're created
+	first := map[string]interface{}{"chart_id": config.UnknownVariableValue, "row": 0, "column": 0, "width": 6, "height": 1, "ignore_filters": false}
+	second := map[string]interface{}{"chart_id": config.UnknownVariableValue, "row": 0, "column": 6, "width": 6, "height": 1, "ignore_filters": false}
+	assert.NotEqual(t, resourceDashboardChartHash(first), resourceDashboardChartHash(second))
+
+	charts := schema.NewSet(resourceDashboardChartHash, []interface{}{first, second})
+	assert.Equal(t, 2, charts.Len())
+
+	first["chart_id"] = ""
+	second["chart_id"] = ""
+	assert.NotEqual(t, resourceDashboardChartHash(first), resourceDashboardChartHash(second))
+}
+
+func TestGetUnmanagedCharts(t *testing.T) {
+	api_charts := []interface{}{
+		map[string]interface{}{"chartId": "A", "row": float64(0), "column": float64(0)},
+		map[string]interface{}{"chartId": "UI", "row": float64(1), "column": float64(0)},
+	}
+	unmanaged := getUnmanagedCharts(api_charts, map[string]bool{"A": true})
+	assert.Equal(t, []interface{}{api_charts[1]}, unmanaged)
+	assert.Equal(t, []interface{}{}, getUnmanagedCharts(api_charts, map[string]bool{"A": true, "UI": true}))
+}
+
+func TestAddUnmanagedCharts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"charts":[{"chartId":"A","row":0,"column":0},{"chartId":"UI","row":1,"column":0}]}`)
+	}))
+	defer server.Close()
+	config := &signalformConfig{APIURL: server.URL}
+
+	payload, err := addUnmanagedCharts(config, server.URL+"/v2/dashboard/DASHID", []byte(`{"charts":[{"chartId":"A","row":0,"column":6}]}`), map[string]bool{"A": true})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"charts":[{"chartId":"A","column":6,"row":0},{"chartId":"UI","column":0,"row":1}]}`, string(payload))
+
+	payload, err = addUnmanagedCharts(config, server.URL+"/v2/dashboard/DASHID", []byte(`{"name":"foo"}`), map[string]bool{"A": true, "UI": true})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"name":"foo"}`, string(payload))
+}
+
+func TestMigrateDashboardStateV0toV1(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "DASHID",
+		Attributes: map[string]string{
+			"chart.#":             "2",
+			"chart.1234.chart_id": "CHARTA",
+			"chart.1234.row":      "0",
+			"chart.1234.column":   "0",
+			"chart.1234.width":    "6",
+			"chart.5678.chart_id": "CHARTB",
+			"chart.5678.row":      "2",
+			"chart.5678.column":   "6",
+			"copied_chart.0.row":  "0",
+			"dashboard_group":     "GROUPID",
+		},
+	}
+	migrated, err := dashboardMigrateState(0, state, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "-1", migrated.Attributes["chart.1234.row"])
+	assert.Equal(t, "-1", migrated.Attributes["chart.1234.column"])
+	assert.Equal(t, "6", migrated.Attributes["chart.1234.width"])
+	assert.Equal(t, "2", migrated.Attributes["chart.5678.row"])
+	assert.Equal(t, "6", migrated.Attributes["chart.5678.column"])
+	// Only the chart blocks had the default changed
+	assert.Equal(t, "0", migrated.Attributes["copied_chart.0.row"])
+
+	_, err = dashboardMigrateState(2, state, nil)
+	assert.Contains(t, err.Error(), "Unexpected schema version")
+}
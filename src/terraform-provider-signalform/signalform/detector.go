@@ -11,8 +11,8 @@ import (
 )
 
 const (
-	DETECTOR_API_URL = "https://api.signalfx.com/v2/detector"
-	DETECTOR_URL     = "https://app.signalfx.com/#/detector/v2/<id>/edit"
+	DETECTOR_API_PATH = "/v2/detector"
+	DETECTOR_URL      = DEFAULT_APP_URL + "/#/detector/v2/<id>/edit"
 )
 
 func detectorResource() *schema.Resource {
@@ -29,6 +29,12 @@ func detectorResource() *schema.Resource {
 				Computed:    true,
 				Description: "Latest timestamp the resource was updated",
 			},
+			"drifted_fields": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Fields of the SignalFx object changed outside of Terraform, found when last refreshed",
+			},
 			"url": &schema.Schema{
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -40,15 +46,17 @@ func detectorResource() *schema.Resource {
 				Default:     DETECTOR_URL,
 				Description: "Base Detector url",
 			},
+			"credential": credentialSchema(),
 			"name": &schema.Schema{
 				Type:        schema.TypeString,
 				Required:    true,
 				Description: "Name of the detector",
 			},
 			"description": &schema.Schema{
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "Description of the detector",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDescription,
+				Description:  "Description of the detector",
 			},
 			"program_text": &schema.Schema{
 				Type:        schema.TypeString,
@@ -98,6 +106,18 @@ func detectorResource() *schema.Resource {
 				Elem:        &schema.Schema{Type: schema.TypeString},
 				Description: "Team IDs to associate the detector to",
 			},
+			"authorized_writer_teams": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Team IDs that can edit the detector. When neither teams nor users are set, anyone can",
+			},
+			"authorized_writer_users": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "User IDs that can edit the detector. When neither teams nor users are set, anyone can",
+			},
 			"rule": &schema.Schema{
 				Type:        schema.TypeSet,
 				Required:    true,
@@ -105,9 +125,10 @@ func detectorResource() *schema.Resource {
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"description": &schema.Schema{
-							Type:        schema.TypeString,
-							Optional:    true,
-							Description: "Description of the rule",
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validateDescription,
+							Description:  "Description of the rule",
 						},
 						"notifications": &schema.Schema{
 							Type:        schema.TypeList,
@@ -169,6 +190,9 @@ func detectorResource() *schema.Resource {
   Use Resource object to construct json payload in order to create a detector
 */
 func getPayloadDetector(d *schema.ResourceData) ([]byte, error) {
+	if err := checkTimeSpan(d); err != nil {
+		return nil, err
+	}
 
 	tf_rules := d.Get("rule").(*schema.Set).List()
 	rules_list := make([]map[string]interface{}, len(tf_rules))
@@ -230,6 +254,10 @@ func getPayloadDetector(d *schema.ResourceData) ([]byte, error) {
 		payload["teams"] = teams
 	}
 
+	if writers := getAuthorizedWriters(d); writers != nil {
+		payload["authorizedWriters"] = writers
+	}
+
 	if val, ok := d.GetOk("tags"); ok {
 		tags := []string{}
 		for _, tag := range val.([]interface{}) {
@@ -298,38 +326,65 @@ func getNotifications(tf_notifications []interface{}) []map[string]interface{} {
 }
 
 func detectorCreate(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
 	payload, err := getPayloadDetector(d)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
+	if err := checkProgramText(config, d); err != nil {
+		return err
+	}
 
-	return resourceCreate(DETECTOR_API_URL, config.AuthToken, payload, d)
+	return resourceCreate(config, apiUrl(config, DETECTOR_API_PATH), config.AuthToken, payload, d)
 }
 
 func detectorRead(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
-	url := fmt.Sprintf("%s/%s", DETECTOR_API_URL, d.Id())
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, DETECTOR_API_PATH), d.Id())
+
+	// The state as it would be sent, to tell what changed outside of Terraform. A state that can't be
+	// sent (e.g. a time span from before its check) is always out of sync.
+	payload, err := getPayloadDetector(d)
+	if err != nil {
+		payload = nil
+	}
 
-	return resourceRead(url, config.AuthToken, d)
+	return resourceRead(config, url, config.AuthToken, payload, d)
 }
 
 func detectorUpdate(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
 	payload, err := getPayloadDetector(d)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
-	url := fmt.Sprintf("%s/%s", DETECTOR_API_URL, d.Id())
+	if d.HasChange("program_text") {
+		if err := checkProgramText(config, d); err != nil {
+			return err
+		}
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, DETECTOR_API_PATH), d.Id())
 
-	return resourceUpdate(url, config.AuthToken, payload, d)
+	return resourceUpdate(config, url, config.AuthToken, payload, d)
 }
 
 func detectorDelete(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
-	url := fmt.Sprintf("%s/%s", DETECTOR_API_URL, d.Id())
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, DETECTOR_API_PATH), d.Id())
 
-	return resourceDelete(url, config.AuthToken, d)
+	return resourceDelete(config, url, config.AuthToken, d)
 }
 
 /*
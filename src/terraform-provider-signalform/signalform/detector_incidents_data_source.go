@@ -0,0 +1,82 @@
+package signalform
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func detectorIncidentsDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"detector_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "ID of the detector",
+			},
+			"incidents": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Currently active incidents of the detector",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the incident",
+						},
+						"detect_label": &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Detect label of the rule that triggered the incident",
+						},
+						"severity": &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Severity of the rule that triggered the incident",
+						},
+						"anomaly_state": &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "State of the incident (e.g. ANOMALOUS)",
+						},
+						"triggered_while_muted": &schema.Schema{
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the incident was triggered while its notifications were muted",
+						},
+					},
+				},
+			},
+		},
+
+		Read: detectorIncidentsRead,
+	}
+}
+
+func detectorIncidentsRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	detectorId := d.Get("detector_id").(string)
+
+	// This endpoint only returns the incidents that are still active
+	found := make([]map[string]interface{}, 0)
+	if err := getApiResource(config, fmt.Sprintf("%s/%s/incidents", apiUrl(config, DETECTOR_API_PATH), detectorId), config.AuthToken, &found); err != nil {
+		return fmt.Errorf("Failed reading the incidents of the detector %s: %s", detectorId, err.Error())
+	}
+
+	incidents := make([]map[string]interface{}, len(found))
+	for i, incident := range found {
+		item := make(map[string]interface{})
+		item["id"] = incident["incidentId"]
+		item["detect_label"] = incident["detectLabel"]
+		item["severity"] = incident["severity"]
+		item["anomaly_state"] = incident["anomalyState"]
+		item["triggered_while_muted"] = incident["triggeredWhileMuted"]
+		incidents[i] = item
+	}
+
+	d.SetId(detectorId)
+	d.Set("incidents", incidents)
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package signalform
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const EVENT_API_PATH = "/v2/event"
+
+func eventsDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"event_type": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Type of the custom events to look for (e.g. deploy)",
+			},
+			"time_range": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				ValidateFunc:  validateSignalfxRelativeTime,
+				Description:   "From when to look for events. SignalFx time syntax (e.g. -5m, -1h). -1d by default",
+				ConflictsWith: []string{"start_time", "end_time"},
+			},
+			"start_time": &schema.Schema{
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Description:   "Seconds since epoch to start looking for events",
+				ConflictsWith: []string{"time_range"},
+			},
+			"end_time": &schema.Schema{
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Description:   "Seconds since epoch to stop looking for events. Now by default",
+				ConflictsWith: []string{"time_range"},
+			},
+			"limit": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     100,
+				Description: "Maximum number of events to return",
+			},
+			"events": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Events found in the time window, most recent first",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the event",
+						},
+						"timestamp": &schema.Schema{
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Seconds since epoch the event happened at",
+						},
+						"dimensions": &schema.Schema{
+							Type:        schema.TypeMap,
+							Computed:    true,
+							Description: "Dimensions of the event",
+						},
+						"properties": &schema.Schema{
+							Type:        schema.TypeMap,
+							Computed:    true,
+							Description: "Properties of the event",
+						},
+					},
+				},
+			},
+		},
+
+		Read: eventsRead,
+	}
+}
+
+func eventsRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+
+	end := time.Now().Unix() * 1000
+	if val, ok := d.GetOk("end_time"); ok {
+		end = int64(val.(int)) * 1000
+	}
+	start := end - 24*60*60*1000
+	if val, ok := d.GetOk("time_range"); ok {
+		if ms, err := fromRangeToMilliSeconds(val.(string)); err == nil {
+			start = end - int64(ms)
+		}
+	} else if val, ok := d.GetOk("start_time"); ok {
+		start = int64(val.(int)) * 1000
+	}
+
+	eventType := d.Get("event_type").(string)
+	query := url.Values{}
+	query.Set("query", fmt.Sprintf("sf_eventType:\"%s\"", eventType))
+	query.Set("startTime", fmt.Sprintf("%d", start))
+	query.Set("endTime", fmt.Sprintf("%d", end))
+	query.Set("limit", fmt.Sprintf("%d", d.Get("limit").(int)))
+
+	found := make([]map[string]interface{}, 0)
+	if err := getApiResource(config, fmt.Sprintf("%s/find?%s", apiUrl(config, EVENT_API_PATH), query.Encode()), config.AuthToken, &found); err != nil {
+		return fmt.Errorf("Failed looking for events of type %s: %s", eventType, err.Error())
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		first, _ := found[i]["timestamp"].(float64)
+		second, _ := found[j]["timestamp"].(float64)
+		return first > second
+	})
+	events := make([]map[string]interface{}, len(found))
+	for i, event := range found {
+		item := make(map[string]interface{})
+		item["id"] = event["id"]
+		if val, ok := event["timestamp"].(float64); ok {
+			item["timestamp"] = int(val / 1000)
+		}
+		item["dimensions"] = getStringMap(event["dimensions"])
+		item["properties"] = getStringMap(event["properties"])
+		events[i] = item
+	}
+
+	d.SetId(fmt.Sprintf("%d", hashcode.String(fmt.Sprintf("%s-%d-%d", eventType, start, end))))
+	d.Set("events", events)
+
+	return nil
+}
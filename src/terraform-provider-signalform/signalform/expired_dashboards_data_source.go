@@ -0,0 +1,97 @@
+package signalform
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func expiredDashboardsDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"dashboards": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Dashboards whose expiration tag has passed, oldest expiration first",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the dashboard",
+						},
+						"name": &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the dashboard",
+						},
+						"expires_at": &schema.Schema{
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Seconds since epoch the dashboard expired at",
+						},
+					},
+				},
+			},
+			"ids": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the expired dashboards, in the same order as dashboards",
+			},
+		},
+
+		Read: expiredDashboardsRead,
+	}
+}
+
+func expiredDashboardsRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+
+	dashboards, err := getApiResults(config, apiUrl(config, DASHBOARD_API_PATH), config.AuthToken)
+	if err != nil {
+		return fmt.Errorf("Failed reading the dashboards: %s", err.Error())
+	}
+
+	expired := expiredDashboards(dashboards, time.Now())
+	ids := make([]string, 0, len(expired))
+	for _, item := range expired {
+		ids = append(ids, item["id"].(string))
+	}
+
+	d.SetId(fmt.Sprintf("%d", hashcode.String(strings.Join(ids, ","))))
+	d.Set("dashboards", expired)
+	d.Set("ids", ids)
+
+	return nil
+}
+
+/*
+  Picks the dashboards tagged with an expiration (see expirationTag) that is passed at now
+*/
+func expiredDashboards(dashboards []map[string]interface{}, now time.Time) []map[string]interface{} {
+	expired := make([]map[string]interface{}, 0)
+	for _, dashboard := range dashboards {
+		tags, _ := dashboard["tags"].([]interface{})
+		for _, tag := range tags {
+			expires_at, ok := parseExpirationTag(fmt.Sprintf("%v", tag))
+			if !ok || !isExpired(expires_at, now) {
+				continue
+			}
+			item := make(map[string]interface{})
+			item["id"] = fmt.Sprintf("%v", dashboard["id"])
+			item["name"] = fmt.Sprintf("%v", dashboard["name"])
+			item["expires_at"] = expires_at
+			expired = append(expired, item)
+			break
+		}
+	}
+	sort.SliceStable(expired, func(i, j int) bool {
+		return expired[i]["expires_at"].(int) < expired[j]["expires_at"].(int)
+	})
+	return expired
+}
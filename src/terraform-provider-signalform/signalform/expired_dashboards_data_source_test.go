@@ -0,0 +1,28 @@
+package signalform
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpiredDashboards(t *testing.T) {
+	dashboards := []map[string]interface{}{
+		map[string]interface{}{"id": "DASH1", "name": "Incident 42", "tags": []interface{}{"incident", "expires:2017-07-14T02:40:00Z"}},
+		map[string]interface{}{"id": "DASH2", "name": "Incident 41", "tags": []interface{}{"expires:2017-07-13T02:40:00Z"}},
+		map[string]interface{}{"id": "DASH3", "name": "Incident 43", "tags": []interface{}{"expires:2017-07-15T02:40:00Z"}},
+		map[string]interface{}{"id": "DASH4", "name": "Service", "tags": []interface{}{"team:sre"}},
+		map[string]interface{}{"id": "DASH5", "name": "Untagged"},
+	}
+
+	expired := expiredDashboards(dashboards, time.Unix(1500000000, 0))
+	assert.Equal(t, 2, len(expired))
+	assert.Equal(t, "DASH2", expired[0]["id"])
+	assert.Equal(t, "Incident 41", expired[0]["name"])
+	assert.Equal(t, 1499913600, expired[0]["expires_at"])
+	assert.Equal(t, "DASH1", expired[1]["id"])
+	assert.Equal(t, 1500000000, expired[1]["expires_at"])
+
+	assert.Equal(t, 0, len(expiredDashboards(dashboards, time.Unix(1499000000, 0))))
+}
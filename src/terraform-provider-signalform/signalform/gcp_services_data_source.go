@@ -0,0 +1,58 @@
+package signalform
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Names of the GCP services supported by the SignalFx GCP integration
+var GcpServiceNames = []string{
+	"appengine",
+	"bigquery",
+	"bigtable",
+	"cloudfunctions",
+	"cloudiot",
+	"cloudsql",
+	"cloudtasks",
+	"compute",
+	"container",
+	"dataflow",
+	"datastore",
+	"firebasedatabase",
+	"firebasehosting",
+	"interconnect",
+	"loadbalancing",
+	"logging",
+	"ml",
+	"monitoring",
+	"pubsub",
+	"router",
+	"serviceruntime",
+	"spanner",
+	"storage",
+	"vpn",
+}
+
+func gcpServicesDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"exclude": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "GCP services to leave out of the list (e.g. logging)",
+			},
+			"services": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Names of the GCP services supported by the SignalFx GCP integration",
+			},
+		},
+
+		Read: gcpServicesRead,
+	}
+}
+
+func gcpServicesRead(d *schema.ResourceData, meta interface{}) error {
+	return servicesRead(GcpServiceNames, d)
+}
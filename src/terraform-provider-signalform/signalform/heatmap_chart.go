@@ -23,6 +23,12 @@ func heatmapChartResource() *schema.Resource {
 				Computed:    true,
 				Description: "Latest timestamp the resource was updated",
 			},
+			"drifted_fields": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Fields of the SignalFx object changed outside of Terraform, found when last refreshed",
+			},
 			"resource_url": &schema.Schema{
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -34,15 +40,17 @@ func heatmapChartResource() *schema.Resource {
 				Computed:    true,
 				Description: "URL of the chart",
 			},
+			"credential": credentialSchema(),
 			"name": &schema.Schema{
 				Type:        schema.TypeString,
 				Required:    true,
 				Description: "Name of the chart",
 			},
 			"description": &schema.Schema{
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "Description of the chart (Optional)",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDescription,
+				Description:  "Description of the chart (Optional)",
 			},
 			"program_text": &schema.Schema{
 				Type:        schema.TypeString,
@@ -50,9 +58,10 @@ func heatmapChartResource() *schema.Resource {
 				Description: "Signalflow program text for the chart. More info at \"https://developers.signalfx.com/docs/signalflow-overview\"",
 			},
 			"unit_prefix": &schema.Schema{
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "(Metric by default) Must be \"Metric\" or \"Binary\"",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateUnitPrefix,
+				Description:  "(Metric by default) Must be \"Metric\" or \"Binary\"",
 			},
 			"minimum_resolution": &schema.Schema{
 				Type:        schema.TypeInt,
@@ -65,6 +74,11 @@ func heatmapChartResource() *schema.Resource {
 				Description:  "How long (in seconds) to wait for late datapoints",
 				ValidateFunc: validateMaxDelayValue,
 			},
+			"timezone": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Time zone the calendar windows of the program are based on (e.g. Europe/Paris), instead of UTC",
+			},
 			"disable_sampling": &schema.Schema{
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -157,10 +171,11 @@ func heatmapChartResource() *schema.Resource {
 			},
 		},
 
-		Create: heatmapchartCreate,
-		Read:   heatmapchartRead,
-		Update: heatmapchartUpdate,
-		Delete: heatmapchartDelete,
+		Create:   heatmapchartCreate,
+		Read:     heatmapchartRead,
+		Update:   heatmapchartUpdate,
+		Delete:   heatmapchartDelete,
+		Importer: chartImporter("Heatmap", getHeatmapChartFieldsFromApi),
 	}
 }
 
@@ -168,6 +183,13 @@ func heatmapChartResource() *schema.Resource {
   Use Resource object to construct json payload in order to create an Heatmap chart
 */
 func getPayloadHeatmapChart(d *schema.ResourceData) ([]byte, error) {
+	if err := checkColorScale(d.Get("color_scale").(*schema.Set).List()); err != nil {
+		return nil, err
+	}
+	if err := checkGroupBy(d.Get("group_by").([]interface{})); err != nil {
+		return nil, err
+	}
+
 	payload := map[string]interface{}{
 		"name":        d.Get("name").(string),
 		"description": d.Get("description").(string),
@@ -223,6 +245,9 @@ func getHeatmapOptionsChart(d *schema.ResourceData) map[string]interface{} {
 	if val, ok := d.GetOk("max_delay"); ok {
 		programOptions["maxDelay"] = val.(int) * 1000
 	}
+	if val, ok := d.GetOk("timezone"); ok {
+		programOptions["timezone"] = val.(string)
+	}
 	programOptions["disableSampling"] = d.Get("disable_sampling").(bool)
 	viz["programOptions"] = programOptions
 
@@ -253,38 +278,130 @@ func getHeatmapOptionsChart(d *schema.ResourceData) map[string]interface{} {
 	return viz
 }
 
+/*
+  Fields of a heatmap chart returned by SignalFx, for its import
+*/
+func getHeatmapChartFieldsFromApi(chart map[string]interface{}) map[string]interface{} {
+	options, _ := chart["options"].(map[string]interface{})
+	fields := make(map[string]interface{})
+
+	fields["program_text"], _ = chart["programText"].(string)
+	fields["unit_prefix"], _ = options["unitPrefix"].(string)
+	setProgramOptionsFromApi(options, fields)
+	fields["group_by"] = getApiStrings(options["groupBy"])
+
+	fields["sort_by"] = ""
+	if property, ok := options["sortProperty"].(string); ok && property != "" {
+		if options["sortDirection"] == "Ascending" {
+			fields["sort_by"] = "+" + property
+		} else {
+			fields["sort_by"] = "-" + property
+		}
+	}
+
+	fields["color_range"] = []interface{}{}
+	fields["color_scale"] = []interface{}{}
+	if options["colorBy"] == "Range" {
+		if colorRange, ok := options["colorRange"].(map[string]interface{}); ok {
+			item := map[string]interface{}{
+				"min_value": float64(-math.MaxFloat32),
+				"max_value": float64(math.MaxFloat32),
+			}
+			if value, ok := colorRange["min"].(float64); ok {
+				item["min_value"] = value
+			}
+			if value, ok := colorRange["max"].(float64); ok {
+				item["max_value"] = value
+			}
+			item["color"], _ = colorRange["color"].(string)
+			fields["color_range"] = []interface{}{item}
+		}
+	} else if options["colorBy"] == "Scale" {
+		fields["color_scale"] = getColorScaleFromApi(options["colorScale2"])
+	}
+
+	fields["hide_timestamp"], _ = options["timestampHidden"].(bool)
+
+	return fields
+}
+
 func heatmapchartCreate(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
 	payload, err := getPayloadHeatmapChart(d)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
+	if err := checkProgramText(config, d); err != nil {
+		return err
+	}
 
-	return resourceCreate(CHART_API_URL, config.AuthToken, payload, d)
+	return resourceCreate(config, apiUrl(config, CHART_API_PATH), config.AuthToken, payload, d)
 }
 
 func heatmapchartRead(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
-	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, CHART_API_PATH), d.Id())
 
-	return resourceRead(url, config.AuthToken, d)
+	// The state as it would be sent, to tell what changed outside of Terraform. A state that can't be
+	// sent (e.g. a color scale from before its check) is always out of sync.
+	payload, err := getPayloadHeatmapChart(d)
+	if err != nil {
+		payload = nil
+	}
+
+	return resourceRead(config, url, config.AuthToken, payload, d)
 }
 
 func heatmapchartUpdate(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
 	payload, err := getPayloadHeatmapChart(d)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
-	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
+	if d.HasChange("program_text") {
+		if err := checkProgramText(config, d); err != nil {
+			return err
+		}
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, CHART_API_PATH), d.Id())
 
-	return resourceUpdate(url, config.AuthToken, payload, d)
+	return resourceUpdate(config, url, config.AuthToken, payload, d)
 }
 
 func heatmapchartDelete(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
-	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
-	return resourceDelete(url, config.AuthToken, d)
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, CHART_API_PATH), d.Id())
+	return chartDelete(config, url, config.AuthToken, d)
+}
+
+/*
+  Checks the properties of group_by: the heatmap nests them in order, so each can only be there once
+*/
+func checkGroupBy(groupBy []interface{}) error {
+	seen := make(map[string]bool)
+	for _, property := range groupBy {
+		property, _ := property.(string)
+		if strings.TrimSpace(property) == "" {
+			return fmt.Errorf("The properties of group_by can't be empty")
+		}
+		if seen[property] {
+			return fmt.Errorf("The property %s is more than once in group_by", property)
+		}
+		seen[property] = true
+	}
+	return nil
 }
 
 /*
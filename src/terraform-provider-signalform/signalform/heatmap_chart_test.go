@@ -14,3 +14,31 @@ func TestValidateHeatmapChartColorsFail(t *testing.T) {
 	_, err := validateHeatmapChartColor("whatever", "color")
 	assert.Equal(t, 1, len(err))
 }
+
+func TestGetHeatmapChartFieldsFromApi(t *testing.T) {
+	fields := getHeatmapChartFieldsFromApi(map[string]interface{}{
+		"programText": "data('cpu.utilization').publish()",
+		"options": map[string]interface{}{
+			"type":          "Heatmap",
+			"groupBy":       []interface{}{"env", "az"},
+			"sortProperty":  "host",
+			"sortDirection": "Ascending",
+			"colorBy":       "Range",
+			"colorRange":    map[string]interface{}{"min": float64(0), "max": float64(100), "color": "blue"},
+		},
+	})
+	assert.Equal(t, []interface{}{"env", "az"}, fields["group_by"])
+	assert.Equal(t, "+host", fields["sort_by"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"min_value": float64(0), "max_value": float64(100), "color": "blue"},
+	}, fields["color_range"])
+	assert.Equal(t, []interface{}{}, fields["color_scale"])
+	assert.Equal(t, false, fields["disable_sampling"])
+}
+
+func TestCheckGroupBy(t *testing.T) {
+	assert.Nil(t, checkGroupBy([]interface{}{"env", "az", "host"}))
+	assert.Nil(t, checkGroupBy([]interface{}{}))
+	assert.Contains(t, checkGroupBy([]interface{}{"env", "az", "env"}).Error(), "The property env is more than once")
+	assert.Contains(t, checkGroupBy([]interface{}{"env", ""}).Error(), "can't be empty")
+}
@@ -0,0 +1,56 @@
+package signalform
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func integrationDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"type": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Type of the integration (e.g. Slack, PagerDuty, AWSCloudWatch)",
+			},
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the integration",
+			},
+			"enabled": &schema.Schema{
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the integration is enabled",
+			},
+		},
+
+		Read: integrationRead,
+	}
+}
+
+func integrationRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	integrationType := d.Get("type").(string)
+	name := d.Get("name").(string)
+
+	query := url.Values{}
+	query.Set("type", integrationType)
+	query.Set("name", name)
+	integrations, err := getApiResults(config, fmt.Sprintf("%s?%s", apiUrl(config, INTEGRATION_API_PATH), query.Encode()), config.AuthToken)
+	if err != nil {
+		return fmt.Errorf("Failed reading the integrations: %s", err.Error())
+	}
+	for _, integration := range integrations {
+		if integration["type"] != integrationType || integration["name"] != name {
+			continue
+		}
+		d.SetId(integration["id"].(string))
+		d.Set("enabled", integration["enabled"])
+		return nil
+	}
+
+	return fmt.Errorf("%s integration %s not found", integrationType, name)
+}
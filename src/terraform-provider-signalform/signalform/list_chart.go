@@ -20,6 +20,12 @@ func listChartResource() *schema.Resource {
 				Computed:    true,
 				Description: "Latest timestamp the resource was updated",
 			},
+			"drifted_fields": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Fields of the SignalFx object changed outside of Terraform, found when last refreshed",
+			},
 			"resource_url": &schema.Schema{
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -31,15 +37,17 @@ func listChartResource() *schema.Resource {
 				Computed:    true,
 				Description: "URL of the chart",
 			},
+			"credential": credentialSchema(),
 			"name": &schema.Schema{
 				Type:        schema.TypeString,
 				Required:    true,
 				Description: "Name of the chart",
 			},
 			"description": &schema.Schema{
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "Description of the chart (Optional)",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDescription,
+				Description:  "Description of the chart (Optional)",
 			},
 			"program_text": &schema.Schema{
 				Type:        schema.TypeString,
@@ -47,14 +55,21 @@ func listChartResource() *schema.Resource {
 				Description: "Signalflow program text for the chart. More info at \"https://developers.signalfx.com/docs/signalflow-overview\"",
 			},
 			"unit_prefix": &schema.Schema{
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "(Metric by default) Must be \"Metric\" or \"Binary\"",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateUnitPrefix,
+				Description:  "(Metric by default) Must be \"Metric\" or \"Binary\"",
 			},
 			"color_by": &schema.Schema{
-				Type:        schema.TypeString,
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateColorBy,
+				Description:  "(Metric by default) Must be \"Metric\" or \"Dimension\"",
+			},
+			"minimum_resolution": &schema.Schema{
+				Type:        schema.TypeInt,
 				Optional:    true,
-				Description: "(Metric by default) Must be \"Metric\" or \"Dimension\"",
+				Description: "The minimum resolution (in seconds) to use for computing the underlying program",
 			},
 			"max_delay": &schema.Schema{
 				Type:         schema.TypeInt,
@@ -62,11 +77,35 @@ func listChartResource() *schema.Resource {
 				Description:  "How long (in seconds) to wait for late datapoints",
 				ValidateFunc: validateMaxDelayValue,
 			},
+			"timezone": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Time zone the calendar windows of the program are based on (e.g. Europe/Paris), instead of UTC",
+			},
 			"disable_sampling": &schema.Schema{
 				Type:        schema.TypeBool,
 				Optional:    true,
 				Description: "(false by default) If false, samples a subset of the output MTS, which improves UI performance",
 			},
+			"time_range": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				ValidateFunc:  validateSignalfxRelativeTime,
+				Description:   "From when to display data. SignalFx time syntax (e.g. -5m, -1h)",
+				ConflictsWith: []string{"start_time", "end_time"},
+			},
+			"start_time": &schema.Schema{
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Description:   "Seconds since epoch to start the visualization",
+				ConflictsWith: []string{"time_range"},
+			},
+			"end_time": &schema.Schema{
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Description:   "Seconds since epoch to end the visualization",
+				ConflictsWith: []string{"time_range"},
+			},
 			"sort_by": &schema.Schema{
 				Type:         schema.TypeString,
 				Optional:     true,
@@ -74,20 +113,30 @@ func listChartResource() *schema.Resource {
 				Description:  "The property to use when sorting the elements. Use 'value' if you want to sort by value. Must be prepended with + for ascending or - for descending (e.g. -foo)",
 			},
 			"refresh_interval": &schema.Schema{
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Description: "How often (in seconds) to refresh the values of the list",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validateRefreshInterval,
+				Description:  "How often (in seconds) to refresh the values of the list",
 			},
 			"legend_fields_to_hide": &schema.Schema{
-				Type:        schema.TypeSet,
-				Optional:    true,
-				Elem:        &schema.Schema{Type: schema.TypeString},
-				Description: "List of properties that shouldn't be displayed in the chart legend (i.e. dimension names)",
+				Type:          schema.TypeSet,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				Description:   "List of properties that shouldn't be displayed in the chart legend (i.e. dimension names)",
+				ConflictsWith: []string{"legend_options_fields"},
 			},
+			"legend_options_fields": legendOptionsFieldsSchema(),
 			"max_precision": &schema.Schema{
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Description: "Maximum number of digits to display when rounding values up or down",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validateMaxPrecision,
+				Description:  "Maximum number of digits to display when rounding values up or down",
+			},
+			"secondary_visualization": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateSecondaryVisualization,
+				Description:  "(None by default) Gauge shown along with the values of the list: \"None\", \"Radial\", \"Linear\" or \"Sparkline\"",
 			},
 			"viz_options": &schema.Schema{
 				Type:        schema.TypeSet,
@@ -100,6 +149,11 @@ func listChartResource() *schema.Resource {
 							Required:    true,
 							Description: "The label used in the publish statement that displays the plot (metric time series data) you want to customize",
 						},
+						"display_name": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Name shown for the plot in the chart and its legend, instead of the label",
+						},
 						"color": &schema.Schema{
 							Type:         schema.TypeString,
 							Optional:     true,
@@ -127,10 +181,11 @@ func listChartResource() *schema.Resource {
 			},
 		},
 
-		Create: listchartCreate,
-		Read:   listchartRead,
-		Update: listchartUpdate,
-		Delete: listchartDelete,
+		Create:   listchartCreate,
+		Read:     listchartRead,
+		Update:   listchartUpdate,
+		Delete:   listchartDelete,
+		Importer: chartImporter("List", getListChartFieldsFromApi),
 	}
 }
 
@@ -138,6 +193,10 @@ func listChartResource() *schema.Resource {
   Use Resource object to construct json payload in order to create a list chart
 */
 func getPayloadListChart(d *schema.ResourceData) ([]byte, error) {
+	if err := checkTimeSpan(d); err != nil {
+		return nil, err
+	}
+
 	payload := map[string]interface{}{
 		"name":        d.Get("name").(string),
 		"description": d.Get("description").(string),
@@ -169,12 +228,22 @@ func getListChartOptions(d *schema.ResourceData) map[string]interface{} {
 	}
 
 	programOptions := make(map[string]interface{})
+	if val, ok := d.GetOk("minimum_resolution"); ok {
+		programOptions["minimumResolution"] = val.(int) * 1000
+	}
 	if val, ok := d.GetOk("max_delay"); ok {
 		programOptions["maxDelay"] = val.(int) * 1000
 	}
+	if val, ok := d.GetOk("timezone"); ok {
+		programOptions["timezone"] = val.(string)
+	}
 	programOptions["disableSampling"] = d.Get("disable_sampling").(bool)
 	viz["programOptions"] = programOptions
 
+	if timeMap := getChartTimeOptions(d); len(timeMap) > 0 {
+		viz["time"] = timeMap
+	}
+
 	if sortBy, ok := d.GetOk("sort_by"); ok {
 		viz["sortBy"] = sortBy.(string)
 	}
@@ -184,41 +253,95 @@ func getListChartOptions(d *schema.ResourceData) map[string]interface{} {
 	if maxPrecision, ok := d.GetOk("max_precision"); ok {
 		viz["maximumPrecision"] = maxPrecision.(int)
 	}
+	if val, ok := d.GetOk("secondary_visualization"); ok {
+		viz["secondaryVisualization"] = val.(string)
+	}
 
 	return viz
 }
 
+/*
+  Fields of a list chart returned by SignalFx, for its import
+*/
+func getListChartFieldsFromApi(chart map[string]interface{}) map[string]interface{} {
+	options, _ := chart["options"].(map[string]interface{})
+	fields := make(map[string]interface{})
+
+	fields["program_text"], _ = chart["programText"].(string)
+	fields["unit_prefix"], _ = options["unitPrefix"].(string)
+	fields["color_by"], _ = options["colorBy"].(string)
+	setProgramOptionsFromApi(options, fields)
+	fields["time_range"], fields["start_time"], fields["end_time"] = getChartTimeFromApi(options["time"])
+	fields["sort_by"], _ = options["sortBy"].(string)
+	fields["refresh_interval"] = fromApiMilliSeconds(options["refreshInterval"])
+	max_precision, _ := options["maximumPrecision"].(float64)
+	fields["max_precision"] = int(max_precision)
+	fields["secondary_visualization"], _ = options["secondaryVisualization"].(string)
+	fields["legend_fields_to_hide"] = []interface{}{}
+	fields["legend_options_fields"] = getLegendOptionsFieldsFromApi(options)
+	fields["viz_options"] = getPerSignalVizOptionsFromApi(options)
+
+	return fields
+}
+
 func listchartCreate(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
 	payload, err := getPayloadListChart(d)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
+	if err := checkProgramText(config, d); err != nil {
+		return err
+	}
 
-	return resourceCreate(CHART_API_URL, config.AuthToken, payload, d)
+	return resourceCreate(config, apiUrl(config, CHART_API_PATH), config.AuthToken, payload, d)
 }
 
 func listchartRead(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
-	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, CHART_API_PATH), d.Id())
+
+	// The state as it would be sent, to tell what changed outside of Terraform. A state that can't be
+	// sent (e.g. a time span from before its check) is always out of sync.
+	payload, err := getPayloadListChart(d)
+	if err != nil {
+		payload = nil
+	}
 
-	return resourceRead(url, config.AuthToken, d)
+	return resourceRead(config, url, config.AuthToken, payload, d)
 }
 
 func listchartUpdate(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
 	payload, err := getPayloadListChart(d)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
-	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
+	if d.HasChange("program_text") {
+		if err := checkProgramText(config, d); err != nil {
+			return err
+		}
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, CHART_API_PATH), d.Id())
 
-	return resourceUpdate(url, config.AuthToken, payload, d)
+	return resourceUpdate(config, url, config.AuthToken, payload, d)
 }
 
 func listchartDelete(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
-	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, CHART_API_PATH), d.Id())
 
-	return resourceDelete(url, config.AuthToken, d)
+	return chartDelete(config, url, config.AuthToken, d)
 }
@@ -0,0 +1,55 @@
+package signalform
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func memberDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"email": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Email address of the member",
+			},
+			"full_name": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Full name of the member",
+			},
+			"admin": &schema.Schema{
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the member is an administrator of the organization",
+			},
+		},
+
+		Read: memberRead,
+	}
+}
+
+func memberRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	email := d.Get("email").(string)
+
+	members, err := getApiResults(config, fmt.Sprintf("%s/member?query=%s", apiUrl(config, ORGANIZATION_API_PATH), url.QueryEscape("email:"+email)), getSessionToken(config))
+	if err != nil {
+		return fmt.Errorf("Failed reading the organization members: %s", err.Error())
+	}
+	for _, member := range members {
+		// Email addresses are case insensitive
+		if memberEmail, _ := member["email"].(string); !strings.EqualFold(memberEmail, email) {
+			continue
+		}
+		d.SetId(member["id"].(string))
+		d.Set("full_name", member["fullName"])
+		d.Set("admin", member["admin"])
+		return nil
+	}
+
+	return fmt.Errorf("Member with email %s not found", email)
+}
@@ -0,0 +1,124 @@
+package signalform
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const INTEGRATION_API_PATH = "/v2/integration"
+
+// Integration types that can be used as detector notification targets
+var NotificationIntegrationTypes = []string{
+	"BigPanda",
+	"Jira",
+	"Office365",
+	"Opsgenie",
+	"PagerDuty",
+	"ServiceNow",
+	"Slack",
+	"VictorOps",
+	"Webhook",
+	"XMatters",
+}
+
+func notificationTargetsDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"type": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateNotificationIntegrationType,
+				Description:  "Only return the notification integrations of this type (e.g. Slack, PagerDuty, Webhook)",
+			},
+			"targets": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Notification integrations configured in the organization",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the integration, used as credential ID in the detector notifications",
+						},
+						"name": &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the integration",
+						},
+						"type": &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Type of the integration",
+						},
+						"enabled": &schema.Schema{
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the integration is enabled",
+						},
+					},
+				},
+			},
+		},
+
+		Read: notificationTargetsRead,
+	}
+}
+
+func notificationTargetsRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+
+	listUrl := apiUrl(config, INTEGRATION_API_PATH)
+	if val, ok := d.GetOk("type"); ok {
+		listUrl = fmt.Sprintf("%s?type=%s", apiUrl(config, INTEGRATION_API_PATH), url.QueryEscape(val.(string)))
+	}
+	integrations, err := getApiResults(config, listUrl, config.AuthToken)
+	if err != nil {
+		return fmt.Errorf("Failed reading the integrations: %s", err.Error())
+	}
+
+	targets := make([]map[string]interface{}, 0)
+	ids := make([]string, 0)
+	for _, integration := range integrations {
+		integrationType, _ := integration["type"].(string)
+		if !isNotificationIntegrationType(integrationType) {
+			continue
+		}
+		item := make(map[string]interface{})
+		item["id"] = integration["id"]
+		item["name"] = integration["name"]
+		item["type"] = integrationType
+		item["enabled"] = integration["enabled"]
+		targets = append(targets, item)
+		ids = append(ids, fmt.Sprintf("%s", integration["id"]))
+	}
+
+	d.SetId(fmt.Sprintf("%d", hashcode.String(strings.Join(ids, ","))))
+	d.Set("targets", targets)
+
+	return nil
+}
+
+func isNotificationIntegrationType(integrationType string) bool {
+	for _, word := range NotificationIntegrationTypes {
+		if integrationType == word {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+  Validates the type field against the integration types usable as notification targets.
+*/
+func validateNotificationIntegrationType(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	if !isNotificationIntegrationType(value) {
+		errors = append(errors, fmt.Errorf("%s not allowed; must be one of: %s", value, strings.Join(NotificationIntegrationTypes, ", ")))
+	}
+	return
+}
@@ -0,0 +1,18 @@
+package signalform
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValidateNotificationIntegrationTypeAllowed(t *testing.T) {
+	for _, value := range []string{"Slack", "PagerDuty", "Webhook"} {
+		_, errors := validateNotificationIntegrationType(value, "type")
+		assert.Equal(t, 0, len(errors))
+	}
+}
+
+func TestValidateNotificationIntegrationTypeNotAllowed(t *testing.T) {
+	_, errors := validateNotificationIntegrationType("AWSCloudWatch", "type")
+	assert.Equal(t, 1, len(errors))
+}
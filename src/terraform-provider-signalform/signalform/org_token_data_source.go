@@ -0,0 +1,86 @@
+package signalform
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func orgTokenDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the org token",
+			},
+			"description": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Description of the org token",
+			},
+			"secret": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "Secret of the org token, e.g. to configure the ingestion agents",
+			},
+			"auth_scopes": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Scopes of the org token (e.g. API, INGEST)",
+			},
+			"disabled": &schema.Schema{
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the org token is disabled",
+			},
+			"dpm_quota": &schema.Schema{
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Datapoints per minute allowed for the org token (0 if unlimited)",
+			},
+			"category_quota": &schema.Schema{
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Metric time series allowed for the org token (0 if unlimited)",
+			},
+		},
+
+		Read: orgTokenRead,
+	}
+}
+
+func orgTokenRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	name := d.Get("name").(string)
+
+	// The name filter of the API does a partial match, so look for the exact one
+	tokens, err := getApiResults(config, fmt.Sprintf("%s?name=%s", apiUrl(config, TOKEN_API_PATH), url.QueryEscape(name)), getSessionToken(config))
+	if err != nil {
+		return fmt.Errorf("Failed reading the org tokens: %s", err.Error())
+	}
+	for _, token := range tokens {
+		if token["name"] != name {
+			continue
+		}
+		d.SetId(name)
+		d.Set("description", token["description"])
+		d.Set("secret", token["secret"])
+		d.Set("auth_scopes", token["authScopes"])
+		d.Set("disabled", token["disabled"])
+		if limits, ok := token["limits"].(map[string]interface{}); ok {
+			if val, ok := limits["dpmQuota"].(float64); ok {
+				d.Set("dpm_quota", int(val))
+			}
+			if val, ok := limits["categoryQuota"].(float64); ok {
+				d.Set("category_quota", int(val))
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("Org token %s not found", name)
+}
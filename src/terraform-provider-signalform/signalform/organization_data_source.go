@@ -0,0 +1,91 @@
+package signalform
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const (
+	ORGANIZATION_API_PATH = "/v2/organization"
+	TOKEN_API_PATH        = "/v2/token"
+)
+
+func organizationDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Name of the organization",
+			},
+			"auth_scopes": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Scopes of the configured auth token (e.g. API, INGEST). Only known if the token is an org token visible to the caller, empty otherwise",
+			},
+		},
+
+		Read: organizationRead,
+	}
+}
+
+func organizationRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+
+	organization := map[string]interface{}{}
+	if err := getApiResource(config, apiUrl(config, ORGANIZATION_API_PATH), config.AuthToken, &organization); err != nil {
+		return fmt.Errorf("Failed reading the organization: %s", err.Error())
+	}
+	id, ok := organization["id"].(string)
+	if !ok {
+		return fmt.Errorf("Failed reading the organization: SignalFx returned no ID")
+	}
+	d.SetId(id)
+	d.Set("name", organization["organizationName"])
+
+	scopes, err := getAuthTokenScopes(config)
+	if err != nil {
+		return err
+	}
+	d.Set("auth_scopes", scopes)
+
+	return nil
+}
+
+/*
+  Scopes of the auth token of the provider. The organization model doesn't say anything about the token
+  in use, so it's looked up among the org tokens. Listing them takes a session token, or an admin one:
+  when the token can't list them its scopes are unknown, and empty.
+*/
+func getAuthTokenScopes(config *signalformConfig) ([]interface{}, error) {
+	scopes := make([]interface{}, 0)
+	tokensUrl := apiUrl(config, TOKEN_API_PATH)
+	status_code, resp_body, err := sendRequest(config, "GET", tokensUrl+"?limit=1", getSessionToken(config), nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading the org tokens: %s", err.Error())
+	}
+	if status_code == 401 || status_code == 403 {
+		log.Printf("[DEBUG] Not allowed to list the org tokens (status %d), the scopes of the auth token are unknown", status_code)
+		return scopes, nil
+	}
+	if status_code != 200 {
+		return nil, fmt.Errorf("For %s SignalFx returned status %d: \n%s", tokensUrl, status_code, resp_body)
+	}
+
+	tokens, err := getApiResults(config, tokensUrl, getSessionToken(config))
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading the org tokens: %s", err.Error())
+	}
+	for _, token := range tokens {
+		if secret, ok := token["secret"].(string); ok && secret == config.AuthToken {
+			if val, ok := token["authScopes"].([]interface{}); ok {
+				scopes = val
+			}
+			break
+		}
+	}
+	return scopes, nil
+}
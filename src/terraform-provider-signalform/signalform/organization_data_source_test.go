@@ -0,0 +1,46 @@
+package signalform
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAuthTokenScopes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/token", r.URL.Path)
+		fmt.Fprint(w, `{"count":2,"results":[{"name":"ingest","secret":"OTHER","authScopes":["INGEST"]},{"name":"api","secret":"TOKEN","authScopes":["API"]}]}`)
+	}))
+	defer server.Close()
+
+	scopes, err := getAuthTokenScopes(&signalformConfig{APIURL: server.URL, AuthToken: "TOKEN"})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"API"}, scopes)
+}
+
+func TestGetAuthTokenScopesForbidden(t *testing.T) {
+	// Org tokens can't list the org tokens
+	for _, status := range []int{401, 403} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+
+		scopes, err := getAuthTokenScopes(&signalformConfig{APIURL: server.URL, AuthToken: "TOKEN"})
+		assert.Nil(t, err)
+		assert.Equal(t, []interface{}{}, scopes)
+		server.Close()
+	}
+}
+
+func TestGetAuthTokenScopesError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+	}))
+	defer server.Close()
+
+	_, err := getAuthTokenScopes(&signalformConfig{APIURL: server.URL, AuthToken: "TOKEN"})
+	assert.Contains(t, err.Error(), "SignalFx returned status 500")
+}
@@ -1,6 +1,8 @@
 package signalform
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"github.com/bgentry/go-netrc/netrc"
@@ -9,17 +11,82 @@ import (
 	"github.com/mitchellh/go-homedir"
 	"io/ioutil"
 	"log"
+	"net/http"
+	"net/url"
 	"os"
 	"os/user"
+	"regexp"
 	"runtime"
+	"strings"
+	"time"
 )
 
 var SystemConfigPath = "/etc/signalfx.conf"
 var HomeConfigSuffix = "/.signalfx.conf"
 var HomeConfigPath = ""
 
+const (
+	DEFAULT_API_URL    = "https://api.signalfx.com"
+	DEFAULT_STREAM_URL = "https://stream.signalfx.com"
+	DEFAULT_INGEST_URL = "https://ingest.signalfx.com"
+	DEFAULT_APP_URL    = "https://app.signalfx.com"
+	SESSION_API_PATH   = "/v2/session"
+)
+
 type signalformConfig struct {
 	AuthToken string `json:"auth_token"`
+	Realm     string `json:"realm"`
+	APIURL    string `json:"api_url"`
+	StreamURL string `json:"stream_url"`
+	IngestURL string `json:"ingest_url"`
+	// Web app of the realm, the host of the resource urls
+	AppURL string `json:"-"`
+	// Version of the API endpoints, v2 by default. APIVersions has the versions of single endpoints (e.g. "dashboard")
+	APIVersion  string            `json:"-"`
+	APIVersions map[string]string `json:"-"`
+	// User credentials, for the API calls that need a session token instead of an org token
+	Email        string `json:"email"`
+	Password     string `json:"password"`
+	SessionToken string `json:"session_token"`
+	// Organization to log in to, for users belonging to several ones
+	OrgID string `json:"org_id"`
+	// Retry policy of the API calls, only set from the provider block
+	MaxRetries   int           `json:"-"`
+	RetryWaitMin time.Duration `json:"-"`
+	RetryWaitMax time.Duration `json:"-"`
+	// "exponential" or "constant"
+	RetryStrategy string `json:"-"`
+	RetryJitter   bool   `json:"-"`
+	// Shared by all the resources, nil when requests are not rate limited
+	RateLimiter *rateLimiter `json:"-"`
+	// Semaphore of the requests in flight, nil when their number is not limited
+	RequestSlots chan struct{} `json:"-"`
+	// Reports the API calls to SignalFx, nil unless self_metrics is enabled
+	SelfMetrics *selfMetrics `json:"-"`
+	// Settings of the HTTP client shared by all the resources
+	Timeout            time.Duration     `json:"-"`
+	ProxyURL           string            `json:"-"`
+	CAFile             string            `json:"-"`
+	InsecureSkipVerify bool              `json:"-"`
+	HTTPClient         *http.Client      `json:"-"`
+	UserAgentSuffix    string            `json:"-"`
+	CustomHeaders      map[string]string `json:"-"`
+	DryRun             bool              `json:"-"`
+	Protect            bool              `json:"-"`
+	// Validate the programs with SignalFlow before sending the charts and detectors
+	ValidateProgramText bool `json:"-"`
+	// Used by the dashboards that don't set their dashboard_group
+	DefaultDashboardGroupID string `json:"default_dashboard_group_id"`
+	// Destroyed dashboards are moved to this dashboard group instead of being deleted
+	ArchiveDashboardGroupID string `json:"-"`
+	// Charts of the dashboards archived by this run, which the chart resources leave in place. Shared
+	// with the copies of the config made for the resources (see resourceConfig).
+	archivedCharts *chartIdSet
+	// The dashboards are saved to this directory before every update and delete, if set
+	DashboardBackupDir string `json:"-"`
+	// Added to the name of everything created by the provider
+	NamePrefix string `json:"-"`
+	NameSuffix string `json:"-"`
 }
 
 func Provider() terraform.ResourceProvider {
@@ -28,8 +95,217 @@ func Provider() terraform.ResourceProvider {
 			"auth_token": &schema.Schema{
 				Type:        schema.TypeString,
 				Optional:    true,
-				DefaultFunc: schema.EnvDefaultFunc("SFX_AUTH_TOKEN", nil),
-				Description: "SignalFx auth token",
+				DefaultFunc: schema.MultiEnvDefaultFunc([]string{"SFX_AUTH_TOKEN", "SIGNALFX_AUTH_TOKEN"}, nil),
+				Description: "SignalFx auth token. Defaults to the SFX_AUTH_TOKEN or SIGNALFX_AUTH_TOKEN environment variables",
+			},
+			"token_file": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Path of a credentials file holding the auth token, either alone or in profiles (e.g. [default] auth_token = XXX)",
+			},
+			"profile": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "default",
+				Description: "Profile of the token_file to read the auth token from",
+			},
+			"realm": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateRealm,
+				Description:  "SignalFx realm of the organization (e.g. us1, eu0). Used to build the API and stream URLs unless they are set",
+			},
+			"api_url": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateSignalfxUrl,
+				Description:  "API URL of SignalFx (e.g. https://api.us1.signalfx.com). https://api.signalfx.com by default",
+			},
+			"stream_url": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateSignalfxUrl,
+				Description:  "Stream URL of SignalFx, used for SignalFlow (e.g. https://stream.us1.signalfx.com). https://stream.signalfx.com by default",
+			},
+			"ingest_url": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateSignalfxUrl,
+				Description:  "Ingest URL of SignalFx, used to send the self metrics (e.g. https://ingest.us1.signalfx.com). https://ingest.signalfx.com by default",
+			},
+			"api_version": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Version of the API endpoints (e.g. v2). Defaults to the version the provider is written against",
+			},
+			"api_versions": &schema.Schema{
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "Versions of single API endpoints, by endpoint name (e.g. dashboard = \"v3\"). They take precedence over api_version",
+			},
+			"email": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Email of the SignalFx user to log in with, to get a session token",
+			},
+			"password": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Password of the SignalFx user to log in with, to get a session token",
+			},
+			"session_token": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("SFX_SESSION_TOKEN", nil),
+				Description: "SignalFx user session token. Used instead of logging in with email and password",
+			},
+			"org_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "ID of the organization to log in to with email and password, for users that are members of several organizations. Only valid along with email and password",
+			},
+			"default_dashboard_group_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "ID of the dashboard group of the dashboards that don't set dashboard_group",
+			},
+			"archive_dashboard_group_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "ID of the dashboard group destroyed dashboards are moved to, instead of being deleted. The charts of the archived dashboards are left in place",
+			},
+			"dashboard_backup_dir": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Directory the JSON of the dashboards is saved to before every update and delete, to restore them after unwanted changes",
+			},
+			"name_prefix": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Text prepended to the name of the detectors, charts, dashboards and dashboard groups (e.g. \"[staging] \")",
+			},
+			"name_suffix": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Text appended to the name of the detectors, charts, dashboards and dashboard groups (e.g. \" (staging)\")",
+			},
+			"max_retries": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     3,
+				Description: "Number of times a request is retried when SignalFx is unavailable (429 and 503 responses), or on network errors, 502 and 504 responses except for POST requests",
+			},
+			"retry_wait_min_seconds": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     1,
+				Description: "Seconds to wait before the first retry. The wait doubles at every retry",
+			},
+			"retry_wait_max_seconds": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     30,
+				Description: "Maximum number of seconds to wait between retries",
+			},
+			"timeout_seconds": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     120,
+				Description: "Timeout of the API calls to SignalFx, in seconds. 0 means no timeout",
+			},
+			"proxy_url": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "URL of the HTTP(S) proxy to reach SignalFx through (e.g. http://proxy.example.com:3128). By default the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are honored",
+			},
+			"ca_file": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Path of a PEM bundle of the certificate authorities to trust, in addition to the system ones (e.g. for proxies intercepting TLS)",
+			},
+			"insecure_skip_verify": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Don't verify the TLS certificate of SignalFx. Only meant for testing, as it makes the connection insecure",
+			},
+			"user_agent_suffix": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Text appended to the User-Agent sent to SignalFx (e.g. the name of the pipeline running terraform)",
+			},
+			"dry_run": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Don't send creations, updates and deletions to SignalFx: their payload is reported as an error instead",
+			},
+			"validate_program_text": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Validate the program_text of the charts and detectors with the SignalFlow preflight endpoint before sending them, to fail with the error of the parser. false by default",
+			},
+			"protect": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Refuse to update or delete anything in SignalFx; only creations are allowed",
+			},
+			"max_concurrent_requests": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validateMaxConcurrentRequests,
+				Description:  "Maximum number of API calls sent to SignalFx at the same time, whatever the terraform parallelism. Unlimited by default",
+			},
+			"self_metrics": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Report the API calls of the provider (signalform.api.calls, signalform.api.errors and signalform.api.latency_ms) to SignalFx",
+			},
+			"self_metrics_token": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Org token with the INGEST scope to send the self metrics with. Defaults to auth_token",
+			},
+			"self_metrics_dimensions": &schema.Schema{
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "Dimensions added to the self metrics (e.g. pipeline = \"deploy-dashboards\")",
+			},
+			"skip_credentials_validation": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Don't check the credentials against the SignalFx API when the provider is configured",
+			},
+			"custom_headers": &schema.Schema{
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "Additional HTTP headers sent with every request, e.g. for API gateways in front of SignalFx",
+			},
+			"retry_strategy": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "exponential",
+				ValidateFunc: validateRetryStrategy,
+				Description:  "How the wait between retries grows: \"exponential\" doubles it at every retry, \"constant\" always waits retry_wait_min_seconds",
+			},
+			"retry_jitter": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Randomize the wait between retries, so that concurrent requests don't retry all at once",
+			},
+			"requests_per_second": &schema.Schema{
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				ValidateFunc: validateRequestsPerSecond,
+				Description:  "Maximum number of API calls per second sent to SignalFx by the provider. Unlimited by default",
 			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
@@ -40,14 +316,31 @@ func Provider() terraform.ResourceProvider {
 			"signalform_list_chart":         listChartResource(),
 			"signalform_text_chart":         textChartResource(),
 			"signalform_dashboard":          dashboardResource(),
+			"signalform_dashboard_json":     dashboardJsonResource(),
 			"signalform_dashboard_group":    dashboardGroupResource(),
 		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"signalform_alert_muting_rule":     alertMutingRuleDataSource(),
+			"signalform_aws_services":          awsServicesDataSource(),
+			"signalform_azure_services":        azureServicesDataSource(),
+			"signalform_dashboard_json":        dashboardJsonDataSource(),
+			"signalform_detector_incidents":    detectorIncidentsDataSource(),
+			"signalform_events":                eventsDataSource(),
+			"signalform_expired_dashboards":    expiredDashboardsDataSource(),
+			"signalform_gcp_services":          gcpServicesDataSource(),
+			"signalform_integration":           integrationDataSource(),
+			"signalform_member":                memberDataSource(),
+			"signalform_notification_targets":  notificationTargetsDataSource(),
+			"signalform_org_token":             orgTokenDataSource(),
+			"signalform_organization":          organizationDataSource(),
+			"signalform_signalflow_validation": signalflowValidationDataSource(),
+		},
 		ConfigureFunc: signalformConfigure,
 	}
 }
 
 func signalformConfigure(data *schema.ResourceData) (interface{}, error) {
-	config := signalformConfig{}
+	config := signalformConfig{archivedCharts: newChartIdSet()}
 
 	// /etc/signalfx.conf has lowest priority
 	log.Printf("[DEBUG] Looking for config in system config (%s)...\n", SystemConfigPath)
@@ -88,12 +381,100 @@ func signalformConfigure(data *schema.ResourceData) (interface{}, error) {
 		log.Printf("[DEBUG] Could not find %s\n", HomeConfigPath)
 	}
 
+	// The API endpoints can be set in the config files too, but the provider has the priority
+	for _, key := range []string{"realm", "api_url", "stream_url", "ingest_url"} {
+		if val, ok := data.GetOk(key); ok {
+			switch key {
+			case "realm":
+				config.Realm = val.(string)
+			case "api_url":
+				config.APIURL = val.(string)
+			case "stream_url":
+				config.StreamURL = val.(string)
+			case "ingest_url":
+				config.IngestURL = val.(string)
+			}
+		}
+	}
+	setApiUrls(&config)
+	if version, ok := data.GetOk("api_version"); ok {
+		config.APIVersion = version.(string)
+	}
+	if versions, ok := data.GetOk("api_versions"); ok {
+		config.APIVersions = map[string]string{}
+		for endpoint, version := range versions.(map[string]interface{}) {
+			config.APIVersions[endpoint] = version.(string)
+		}
+	}
+	log.Printf("[DEBUG] Using API url %s and stream url %s\n", config.APIURL, config.StreamURL)
+
+	config.MaxRetries = data.Get("max_retries").(int)
+	config.RetryWaitMin = time.Duration(data.Get("retry_wait_min_seconds").(int)) * time.Second
+	config.RetryWaitMax = time.Duration(data.Get("retry_wait_max_seconds").(int)) * time.Second
+	config.RetryStrategy = data.Get("retry_strategy").(string)
+	config.RetryJitter = data.Get("retry_jitter").(bool)
+	config.Timeout = time.Duration(data.Get("timeout_seconds").(int)) * time.Second
+	if proxyUrl, ok := data.GetOk("proxy_url"); ok {
+		config.ProxyURL = proxyUrl.(string)
+	}
+	if caFile, ok := data.GetOk("ca_file"); ok {
+		config.CAFile = caFile.(string)
+	}
+	config.InsecureSkipVerify = data.Get("insecure_skip_verify").(bool)
+	httpClient, err := newHttpClient(&config)
+	if err != nil {
+		return nil, err
+	}
+	config.HTTPClient = httpClient
+	if headers, ok := data.GetOk("custom_headers"); ok {
+		config.CustomHeaders = map[string]string{}
+		for name, value := range headers.(map[string]interface{}) {
+			config.CustomHeaders[name] = value.(string)
+		}
+	}
+	if suffix, ok := data.GetOk("user_agent_suffix"); ok {
+		config.UserAgentSuffix = suffix.(string)
+	}
+	config.DryRun = data.Get("dry_run").(bool)
+	config.Protect = data.Get("protect").(bool)
+	config.ValidateProgramText = data.Get("validate_program_text").(bool)
+	if groupId, ok := data.GetOk("archive_dashboard_group_id"); ok {
+		config.ArchiveDashboardGroupID = groupId.(string)
+	}
+	if dir, ok := data.GetOk("dashboard_backup_dir"); ok {
+		config.DashboardBackupDir = dir.(string)
+	}
+	if prefix, ok := data.GetOk("name_prefix"); ok {
+		config.NamePrefix = prefix.(string)
+	}
+	if suffix, ok := data.GetOk("name_suffix"); ok {
+		config.NameSuffix = suffix.(string)
+	}
+	if groupId, ok := data.GetOk("default_dashboard_group_id"); ok {
+		config.DefaultDashboardGroupID = groupId.(string)
+	}
+	if rps, ok := data.GetOk("requests_per_second"); ok {
+		config.RateLimiter = newRateLimiter(rps.(float64))
+	}
+	if maxRequests, ok := data.GetOk("max_concurrent_requests"); ok {
+		config.RequestSlots = make(chan struct{}, maxRequests.(int))
+	}
+
 	// Use netrc next
-	err := readNetrcFile(&config)
+	err = readNetrcFile(&config)
 	if err != nil {
 		return nil, err
 	}
 
+	// then the credentials file set in the provider
+	if tokenFile, ok := data.GetOk("token_file"); ok {
+		token, err := readTokenFile(tokenFile.(string), data.Get("profile").(string))
+		if err != nil {
+			return nil, err
+		}
+		config.AuthToken = token
+	}
+
 	// provider is the top priority
 	if token, ok := data.GetOk("auth_token"); ok {
 		log.Printf("[DEBUG] Reading config from provider.\n")
@@ -103,6 +484,36 @@ func signalformConfigure(data *schema.ResourceData) (interface{}, error) {
 		log.Printf("[DEBUG] Did not find config in provider.\n")
 	}
 
+	for _, key := range []string{"email", "password", "session_token", "org_id"} {
+		if val, ok := data.GetOk(key); ok {
+			switch key {
+			case "email":
+				config.Email = val.(string)
+			case "password":
+				config.Password = val.(string)
+			case "session_token":
+				config.SessionToken = val.(string)
+			case "org_id":
+				config.OrgID = val.(string)
+			}
+		}
+	}
+	if err := checkOrgId(&config); err != nil {
+		return &config, err
+	}
+	if config.SessionToken == "" && config.Email != "" && config.Password != "" {
+		log.Printf("[DEBUG] Logging in to SignalFx as %s\n", config.Email)
+		if err := createSessionToken(&config); err != nil {
+			return &config, err
+		}
+	}
+
+	// A session token can do anything an org token does, so it is good enough on its own
+	if len(config.AuthToken) == 0 && len(config.SessionToken) > 0 {
+		log.Printf("[DEBUG] Using the session token as auth token")
+		config.AuthToken = config.SessionToken
+	}
+
 	if len(config.AuthToken) == 0 {
 		log.Printf("[DEBUG] config.AuthToken has length %d", len(config.AuthToken))
 		return &config, fmt.Errorf("auth_token: required field is not set")
@@ -110,6 +521,28 @@ func signalformConfigure(data *schema.ResourceData) (interface{}, error) {
 		log.Printf("[DEBUG] config.AuthToken is longer than 0 bytes")
 	}
 
+	if !data.Get("skip_credentials_validation").(bool) {
+		if err := validateCredentials(&config); err != nil {
+			return &config, err
+		}
+	}
+
+	// Set up last, so that the API calls of the configuration itself are not reported
+	if data.Get("self_metrics").(bool) {
+		token := config.AuthToken
+		if val, ok := data.GetOk("self_metrics_token"); ok {
+			token = val.(string)
+		}
+		dimensions := map[string]string{}
+		if val, ok := data.GetOk("self_metrics_dimensions"); ok {
+			for name, value := range val.(map[string]interface{}) {
+				dimensions[name] = value.(string)
+			}
+		}
+		config.SelfMetrics = newSelfMetrics(config.IngestURL, token, dimensions, config.HTTPClient)
+		registerSelfMetrics(config.SelfMetrics)
+	}
+
 	return &config, nil
 }
 
@@ -125,6 +558,43 @@ func readConfigFile(configPath string, config *signalformConfig) error {
 	return nil
 }
 
+/*
+  Reads the auth token from a credentials file. The file either contains just the token, or profiles like:
+
+    [default]
+    auth_token = XXX
+
+    [staging]
+    auth_token = YYY
+*/
+func readTokenFile(path string, profile string) (string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("Failed to open token file. %s", err.Error())
+	}
+	text := strings.TrimSpace(string(content))
+	if text != "" && !strings.ContainsAny(text, "=[\n") {
+		return text, nil
+	}
+
+	section := "default"
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) == 2 && section == profile && strings.TrimSpace(parts[0]) == "auth_token" {
+			return strings.TrimSpace(parts[1]), nil
+		}
+	}
+	return "", fmt.Errorf("No auth_token found for profile %s in token file %s", profile, path)
+}
+
 func readNetrcFile(config *signalformConfig) error {
 	// Inspired by https://github.com/hashicorp/terraform/blob/master/vendor/github.com/hashicorp/go-getter/netrc.go
 	// Get the netrc file path
@@ -162,7 +632,11 @@ func readNetrcFile(config *signalformConfig) error {
 		return fmt.Errorf("Error parsing netrc file at %q: %s", path, err)
 	}
 
-	machine := net.FindMachine("api.signalfx.com")
+	host := "api.signalfx.com"
+	if apiUrl, err := url.Parse(config.APIURL); err == nil && apiUrl.Host != "" {
+		host = apiUrl.Host
+	}
+	machine := net.FindMachine(host)
 	if machine == nil {
 		// Machine not found, no problem
 		return nil
@@ -172,3 +646,232 @@ func readNetrcFile(config *signalformConfig) error {
 	config.AuthToken = machine.Password
 	return nil
 }
+
+/*
+  The organization is only picked when logging in, so org_id can't do anything for the session
+  and org tokens: it would be ignored silently otherwise.
+*/
+func checkOrgId(config *signalformConfig) error {
+	if config.OrgID == "" {
+		return nil
+	}
+	if config.Email == "" || config.Password == "" {
+		return fmt.Errorf("org_id is only used to log in with email and password, which are not set. Tokens always belong to a single organization")
+	}
+	if config.SessionToken != "" {
+		return fmt.Errorf("org_id is only used to log in with email and password, and session_token is set. Remove session_token, or org_id")
+	}
+	return nil
+}
+
+/*
+  Logs in to SignalFx with the user email and password, storing the session token in the config.
+  The session is bound to the configured organization, or to the default one of the user.
+*/
+func createSessionToken(config *signalformConfig) error {
+	credentials := map[string]string{
+		"email":    config.Email,
+		"password": config.Password,
+	}
+	if config.OrgID != "" {
+		credentials["organizationId"] = config.OrgID
+	}
+	payload, err := json.Marshal(credentials)
+	if err != nil {
+		return fmt.Errorf("Failed creating json payload: %s", err.Error())
+	}
+	status_code, resp_body, err := sendRequest(config, "POST", apiUrl(config, SESSION_API_PATH), "", payload)
+	if err != nil {
+		return err
+	}
+	if status_code != 200 {
+		return fmt.Errorf("Failed logging in to SignalFx as %s: status %d", config.Email, status_code)
+	}
+	session := struct {
+		AccessToken string `json:"accessToken"`
+	}{}
+	if err := json.Unmarshal(resp_body, &session); err != nil {
+		return fmt.Errorf("Failed unmarshaling the session for %s: %s", config.Email, err.Error())
+	}
+	config.SessionToken = session.AccessToken
+	return nil
+}
+
+/*
+  Reads the organization, the cheapest API call there is, to fail early on invalid credentials
+  instead of on the first resource
+*/
+func validateCredentials(config *signalformConfig) error {
+	status_code, resp_body, err := sendRequest(config, "GET", apiUrl(config, ORGANIZATION_API_PATH), config.AuthToken, nil)
+	if err != nil {
+		return fmt.Errorf("Failed checking the credentials against %s: %s", config.APIURL, err.Error())
+	}
+	if status_code == 401 {
+		return fmt.Errorf("auth_token is not valid for %s: it is either wrong, expired, or from another realm", config.APIURL)
+	}
+	if status_code != 200 {
+		return fmt.Errorf("Failed checking the credentials against %s: SignalFx returned status %d: \n%s", config.APIURL, status_code, resp_body)
+	}
+	return nil
+}
+
+/*
+  Returns the session token for the API calls that require a user session (e.g. managing org tokens),
+  falling back to the auth token when no user credentials are configured.
+*/
+func getSessionToken(config *signalformConfig) string {
+	if config.SessionToken != "" {
+		return config.SessionToken
+	}
+	return config.AuthToken
+}
+
+/*
+  Config of the provider for a resource, with the credential the resource picked: the auth token by
+  default, or the session token of the user with credential = "session_token". The copy shares
+  everything else with the config of the provider.
+*/
+func resourceConfig(d *schema.ResourceData, meta interface{}) (*signalformConfig, error) {
+	config := meta.(*signalformConfig)
+	if d.Get("credential") != "session_token" {
+		return config, nil
+	}
+	if config.SessionToken == "" {
+		return nil, fmt.Errorf("The resource %s uses credential = \"session_token\", but the provider has no session token: set session_token, or email and password", d.Get("name"))
+	}
+	withSession := *config
+	withSession.AuthToken = config.SessionToken
+	return &withSession, nil
+}
+
+/*
+  Builds the HTTP client shared by all the resources from the provider settings
+*/
+func newHttpClient(config *signalformConfig) (*http.Client, error) {
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+	if config.ProxyURL != "" {
+		proxyUrl, err := url.Parse(config.ProxyURL)
+		if err != nil || proxyUrl.Host == "" {
+			return nil, fmt.Errorf("Invalid proxy_url %s", config.ProxyURL)
+		}
+		transport.Proxy = http.ProxyURL(proxyUrl)
+	}
+	if config.CAFile != "" || config.InsecureSkipVerify {
+		tlsConfig := &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify}
+		if config.CAFile != "" {
+			pem, err := ioutil.ReadFile(config.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("Failed reading ca_file: %s", err.Error())
+			}
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("No certificates found in ca_file %s", config.CAFile)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	return &http.Client{
+		Timeout:   config.Timeout,
+		Transport: transport,
+	}, nil
+}
+
+/*
+  Fills in the API, stream and ingest urls from the realm, when they are not explicitly set, and the app url
+*/
+func setApiUrls(config *signalformConfig) {
+	if config.APIURL == "" {
+		if config.Realm == "" {
+			config.APIURL = DEFAULT_API_URL
+		} else {
+			config.APIURL = fmt.Sprintf("https://api.%s.signalfx.com", config.Realm)
+		}
+	}
+	if config.StreamURL == "" {
+		if config.Realm == "" {
+			config.StreamURL = DEFAULT_STREAM_URL
+		} else {
+			config.StreamURL = fmt.Sprintf("https://stream.%s.signalfx.com", config.Realm)
+		}
+	}
+	if config.IngestURL == "" {
+		if config.Realm == "" {
+			config.IngestURL = DEFAULT_INGEST_URL
+		} else {
+			config.IngestURL = fmt.Sprintf("https://ingest.%s.signalfx.com", config.Realm)
+		}
+	}
+	if config.Realm == "" {
+		config.AppURL = DEFAULT_APP_URL
+	} else {
+		config.AppURL = fmt.Sprintf("https://app.%s.signalfx.com", config.Realm)
+	}
+	config.APIURL = strings.TrimSuffix(config.APIURL, "/")
+	config.StreamURL = strings.TrimSuffix(config.StreamURL, "/")
+	config.IngestURL = strings.TrimSuffix(config.IngestURL, "/")
+}
+
+/*
+  Validates the realm field (e.g. us1, eu0)
+*/
+func validateRealm(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	if !regexp.MustCompile("^[a-z]+[0-9]+$").MatchString(value) {
+		errors = append(errors, fmt.Errorf("%s not allowed; must be a SignalFx realm (e.g. us1, eu0)", value))
+	}
+	return
+}
+
+/*
+  Validates a URL of SignalFx (e.g. api_url); it must have a scheme and a host, e.g. https://api.us1.signalfx.com
+*/
+func validateSignalfxUrl(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	parsed, err := url.Parse(value)
+	if err != nil {
+		errors = append(errors, fmt.Errorf("%s not allowed; %s must be a URL: %s", value, k, err.Error()))
+	} else if parsed.Scheme == "" || parsed.Host == "" {
+		errors = append(errors, fmt.Errorf("%s not allowed; %s must be a URL with a scheme and a host (e.g. https://api.us1.signalfx.com)", value, k))
+	}
+	return
+}
+
+/*
+  Validates the requests_per_second field; it must be positive
+*/
+func validateRequestsPerSecond(v interface{}, k string) (we []string, errors []error) {
+	value := v.(float64)
+	if value <= 0 {
+		errors = append(errors, fmt.Errorf("%f not allowed; requests_per_second must be > 0", value))
+	}
+	return
+}
+
+/*
+  Validates the max_concurrent_requests field; it must be positive
+*/
+func validateMaxConcurrentRequests(v interface{}, k string) (we []string, errors []error) {
+	value := v.(int)
+	if value <= 0 {
+		errors = append(errors, fmt.Errorf("%d not allowed; max_concurrent_requests must be > 0", value))
+	}
+	return
+}
+
+/*
+  Validates the retry_strategy field against the supported strategies
+*/
+func validateRetryStrategy(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	if value != "exponential" && value != "constant" {
+		errors = append(errors, fmt.Errorf("%s not allowed; must be either exponential or constant", value))
+	}
+	return
+}
@@ -1,14 +1,18 @@
 package signalform
 
 import (
+	"encoding/pem"
 	"fmt"
 	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 )
 
 var OldSystemConfigPath = SystemConfigPath
@@ -72,7 +76,8 @@ func TestProviderConfigureFromTerraform(t *testing.T) {
 	os.Setenv("SFX_AUTH_TOKEN", "YYY")
 	defer os.Unsetenv("SFX_AUTH_TOKEN")
 	raw := map[string]interface{}{
-		"auth_token": "XXX",
+		"auth_token":                  "XXX",
+		"skip_credentials_validation": true,
 	}
 	rawConfig, err := config.NewRawConfig(raw)
 	if err != nil {
@@ -94,7 +99,8 @@ func TestProviderConfigureFromTerraformOnly(t *testing.T) {
 	SystemConfigPath = "filedoesnotexist"
 	HomeConfigPath = "filedoesnotexist"
 	raw := map[string]interface{}{
-		"auth_token": "XXX",
+		"auth_token":                  "XXX",
+		"skip_credentials_validation": true,
 	}
 	rawConfig, err := config.NewRawConfig(raw)
 	if err != nil {
@@ -126,7 +132,9 @@ func TestProviderConfigureFromEnvironment(t *testing.T) {
 	defer os.Remove(tmpfileHome.Name())
 	os.Setenv("SFX_AUTH_TOKEN", "YYY")
 	defer os.Unsetenv("SFX_AUTH_TOKEN")
-	raw := make(map[string]interface{})
+	raw := map[string]interface{}{
+		"skip_credentials_validation": true,
+	}
 	rawConfig, err := config.NewRawConfig(raw)
 	if err != nil {
 		t.Fatalf("Error creating mock config: %s", err.Error())
@@ -148,7 +156,33 @@ func TestProviderConfigureFromEnvironmentOnly(t *testing.T) {
 	HomeConfigPath = "filedoesnotexist"
 	os.Setenv("SFX_AUTH_TOKEN", "YYY")
 	defer os.Unsetenv("SFX_AUTH_TOKEN")
-	raw := make(map[string]interface{})
+	raw := map[string]interface{}{
+		"skip_credentials_validation": true,
+	}
+	rawConfig, err := config.NewRawConfig(raw)
+	if err != nil {
+		t.Fatalf("Error creating mock config: %s", err.Error())
+	}
+
+	rp := Provider()
+	err = rp.Configure(terraform.NewResourceConfig(rawConfig))
+	meta := rp.(*schema.Provider).Meta()
+	if meta == nil {
+		t.Fatalf("Expected metadata, got nil. err: %s", err.Error())
+	}
+	configuration := meta.(*signalformConfig)
+	assert.Equal(t, "YYY", configuration.AuthToken)
+}
+
+func TestProviderConfigureFromSignalfxEnvironmentOnly(t *testing.T) {
+	defer resetGlobals()
+	SystemConfigPath = "filedoesnotexist"
+	HomeConfigPath = "filedoesnotexist"
+	os.Setenv("SIGNALFX_AUTH_TOKEN", "YYY")
+	defer os.Unsetenv("SIGNALFX_AUTH_TOKEN")
+	raw := map[string]interface{}{
+		"skip_credentials_validation": true,
+	}
 	rawConfig, err := config.NewRawConfig(raw)
 	if err != nil {
 		t.Fatalf("Error creating mock config: %s", err.Error())
@@ -178,7 +212,9 @@ func TestSignalformConfigureFromHomeFile(t *testing.T) {
 	}
 	defer os.Remove(tmpfileHome.Name())
 	HomeConfigPath = tmpfileHome.Name()
-	raw := make(map[string]interface{})
+	raw := map[string]interface{}{
+		"skip_credentials_validation": true,
+	}
 	rawConfig, err := config.NewRawConfig(raw)
 	if err != nil {
 		t.Fatalf("Error creating mock config: %s", err.Error())
@@ -209,7 +245,9 @@ func TestSignalformConfigureFromNetrcFile(t *testing.T) {
 	defer os.Remove(tmpfileHome.Name())
 	os.Setenv("NETRC", tmpfileHome.Name())
 	defer os.Unsetenv("NETRC")
-	raw := make(map[string]interface{})
+	raw := map[string]interface{}{
+		"skip_credentials_validation": true,
+	}
 	rawConfig, err := config.NewRawConfig(raw)
 	if err != nil {
 		t.Fatalf("Error creating mock config: %s", err.Error())
@@ -234,7 +272,9 @@ func TestSignalformConfigureFromHomeFileOnly(t *testing.T) {
 	}
 	defer os.Remove(tmpfileHome.Name())
 	HomeConfigPath = tmpfileHome.Name()
-	raw := make(map[string]interface{})
+	raw := map[string]interface{}{
+		"skip_credentials_validation": true,
+	}
 	rawConfig, err := config.NewRawConfig(raw)
 	if err != nil {
 		t.Fatalf("Error creating mock config: %s", err.Error())
@@ -259,7 +299,9 @@ func TestSignalformConfigureFromSystemFileOnly(t *testing.T) {
 	defer os.Remove(tmpfileSystem.Name())
 	SystemConfigPath = tmpfileSystem.Name()
 	HomeConfigPath = "filedoesnotexist"
-	raw := make(map[string]interface{})
+	raw := map[string]interface{}{
+		"skip_credentials_validation": true,
+	}
 	rawConfig, err := config.NewRawConfig(raw)
 	if err != nil {
 		t.Fatalf("Error creating mock config: %s", err.Error())
@@ -275,6 +317,273 @@ func TestSignalformConfigureFromSystemFileOnly(t *testing.T) {
 	assert.Equal(t, "ZZZ", configuration.AuthToken)
 }
 
+func TestSetApiUrlsDefault(t *testing.T) {
+	config := signalformConfig{}
+	setApiUrls(&config)
+	assert.Equal(t, "https://api.signalfx.com", config.APIURL)
+	assert.Equal(t, "https://stream.signalfx.com", config.StreamURL)
+	assert.Equal(t, "https://ingest.signalfx.com", config.IngestURL)
+	assert.Equal(t, "https://app.signalfx.com", config.AppURL)
+}
+
+func TestSetApiUrlsFromRealm(t *testing.T) {
+	config := signalformConfig{Realm: "eu0"}
+	setApiUrls(&config)
+	assert.Equal(t, "https://api.eu0.signalfx.com", config.APIURL)
+	assert.Equal(t, "https://stream.eu0.signalfx.com", config.StreamURL)
+	assert.Equal(t, "https://ingest.eu0.signalfx.com", config.IngestURL)
+	assert.Equal(t, "https://app.eu0.signalfx.com", config.AppURL)
+}
+
+func TestSetApiUrlsExplicit(t *testing.T) {
+	config := signalformConfig{Realm: "eu0", APIURL: "https://signalfx.example.com/"}
+	setApiUrls(&config)
+	assert.Equal(t, "https://signalfx.example.com", config.APIURL)
+	assert.Equal(t, "https://stream.eu0.signalfx.com", config.StreamURL)
+}
+
+func TestValidateRealmAllowed(t *testing.T) {
+	for _, value := range []string{"us0", "us1", "eu0", "ap0"} {
+		_, errors := validateRealm(value, "realm")
+		assert.Equal(t, 0, len(errors))
+	}
+}
+
+func TestValidateRealmNotAllowed(t *testing.T) {
+	_, errors := validateRealm("api.us1.signalfx.com", "realm")
+	assert.Equal(t, 1, len(errors))
+}
+
+func TestValidateSignalfxUrlAllowed(t *testing.T) {
+	for _, value := range []string{"https://api.us1.signalfx.com", "http://localhost:8080", "https://proxy.example.com/signalfx"} {
+		_, errors := validateSignalfxUrl(value, "api_url")
+		assert.Equal(t, 0, len(errors), value)
+	}
+}
+
+func TestValidateSignalfxUrlNotAllowed(t *testing.T) {
+	for _, value := range []string{"api.signalfx.com:443x", "https://api.signalfx.com:443x", "api.signalfx.com", "https://", "://api.signalfx.com"} {
+		_, errors := validateSignalfxUrl(value, "api_url")
+		assert.Equal(t, 1, len(errors), value)
+	}
+}
+
+func TestCreateSessionTokenSuccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/v2/session", r.URL.Path)
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(t, `{"email":"user@example.com","password":"secret"}`, string(body))
+		fmt.Fprint(w, `{"accessToken":"SESSION"}`)
+	}))
+	defer server.Close()
+
+	config := signalformConfig{APIURL: server.URL, Email: "user@example.com", Password: "secret"}
+	err := createSessionToken(&config)
+	assert.Nil(t, err)
+	assert.Equal(t, "SESSION", config.SessionToken)
+}
+
+func TestCreateSessionTokenOrganization(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(t, `{"email":"user@example.com","password":"secret","organizationId":"ORGID"}`, string(body))
+		fmt.Fprint(w, `{"accessToken":"SESSION"}`)
+	}))
+	defer server.Close()
+
+	config := signalformConfig{APIURL: server.URL, Email: "user@example.com", Password: "secret", OrgID: "ORGID"}
+	err := createSessionToken(&config)
+	assert.Nil(t, err)
+	assert.Equal(t, "SESSION", config.SessionToken)
+}
+
+func TestCreateSessionTokenUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(401)
+	}))
+	defer server.Close()
+
+	config := signalformConfig{APIURL: server.URL, Email: "user@example.com", Password: "wrong"}
+	err := createSessionToken(&config)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "status 401")
+	assert.Equal(t, "", config.SessionToken)
+}
+
+func TestCheckOrgId(t *testing.T) {
+	assert.Nil(t, checkOrgId(&signalformConfig{AuthToken: "ORG"}))
+	assert.Nil(t, checkOrgId(&signalformConfig{Email: "user@example.com", Password: "secret", OrgID: "ORGID"}))
+
+	err := checkOrgId(&signalformConfig{AuthToken: "ORG", OrgID: "ORGID"})
+	assert.Contains(t, err.Error(), "org_id is only used to log in with email and password, which are not set")
+	err = checkOrgId(&signalformConfig{Email: "user@example.com", Password: "secret", SessionToken: "SESSION", OrgID: "ORGID"})
+	assert.Contains(t, err.Error(), "session_token is set")
+}
+
+func TestGetSessionToken(t *testing.T) {
+	assert.Equal(t, "SESSION", getSessionToken(&signalformConfig{AuthToken: "ORG", SessionToken: "SESSION"}))
+	assert.Equal(t, "ORG", getSessionToken(&signalformConfig{AuthToken: "ORG"}))
+}
+
+func TestResourceConfig(t *testing.T) {
+	config := &signalformConfig{AuthToken: "ORG", SessionToken: "SESSION"}
+
+	d := schema.TestResourceDataRaw(t, textChartResource().Schema, map[string]interface{}{"name": "foo", "markdown": "bar"})
+	resource, err := resourceConfig(d, config)
+	assert.Nil(t, err)
+	assert.Equal(t, config, resource)
+}
+
+func TestValidateCredential(t *testing.T) {
+	for _, value := range []string{"auth_token", "session_token"} {
+		_, errors := validateCredential(value, "credential")
+		assert.Equal(t, 0, len(errors))
+	}
+	_, errors := validateCredential("password", "credential")
+	assert.Equal(t, 1, len(errors))
+}
+
+func TestValidateRequestsPerSecond(t *testing.T) {
+	_, errors := validateRequestsPerSecond(2.5, "requests_per_second")
+	assert.Equal(t, 0, len(errors))
+	_, errors = validateRequestsPerSecond(0.0, "requests_per_second")
+	assert.Equal(t, 1, len(errors))
+}
+
+func TestNewHttpClient(t *testing.T) {
+	client, err := newHttpClient(&signalformConfig{Timeout: 30 * time.Second})
+	assert.Nil(t, err)
+	assert.Equal(t, 30*time.Second, client.Timeout)
+}
+
+func TestNewHttpClientProxy(t *testing.T) {
+	client, err := newHttpClient(&signalformConfig{ProxyURL: "http://proxy.example.com:3128"})
+	assert.Nil(t, err)
+	req, _ := http.NewRequest("GET", "https://api.signalfx.com/v2/chart", nil)
+	proxyUrl, err := client.Transport.(*http.Transport).Proxy(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "proxy.example.com:3128", proxyUrl.Host)
+}
+
+func TestNewHttpClientInvalidProxy(t *testing.T) {
+	_, err := newHttpClient(&signalformConfig{ProxyURL: "proxy.example.com:3128"})
+	assert.NotNil(t, err)
+}
+
+func TestNewHttpClientCAFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+	caFile, err := createTempConfigFile(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})), "ca.pem")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.Remove(caFile.Name())
+
+	client, err := newHttpClient(&signalformConfig{CAFile: caFile.Name()})
+	assert.Nil(t, err)
+	status_code, _, err := sendRequest(&signalformConfig{HTTPClient: client}, "GET", server.URL, "token", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, status_code)
+}
+
+func TestNewHttpClientInvalidCAFile(t *testing.T) {
+	caFile, err := createTempConfigFile("not a certificate", "ca.pem")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.Remove(caFile.Name())
+
+	_, err = newHttpClient(&signalformConfig{CAFile: caFile.Name()})
+	assert.Contains(t, err.Error(), "No certificates found")
+}
+
+func TestNewHttpClientInsecureSkipVerify(t *testing.T) {
+	client, err := newHttpClient(&signalformConfig{InsecureSkipVerify: true})
+	assert.Nil(t, err)
+	assert.True(t, client.Transport.(*http.Transport).TLSClientConfig.InsecureSkipVerify)
+}
+
+func TestReadTokenFileRawToken(t *testing.T) {
+	tmpfile, err := createTempConfigFile("XXX\n", "token")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.Remove(tmpfile.Name())
+
+	token, err := readTokenFile(tmpfile.Name(), "default")
+	assert.Nil(t, err)
+	assert.Equal(t, "XXX", token)
+}
+
+func TestReadTokenFileProfiles(t *testing.T) {
+	tmpfile, err := createTempConfigFile(`# SignalFx credentials
+[default]
+auth_token = XXX
+
+[staging]
+auth_token=YYY
+`, "credentials")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.Remove(tmpfile.Name())
+
+	token, err := readTokenFile(tmpfile.Name(), "default")
+	assert.Nil(t, err)
+	assert.Equal(t, "XXX", token)
+	token, err = readTokenFile(tmpfile.Name(), "staging")
+	assert.Nil(t, err)
+	assert.Equal(t, "YYY", token)
+	_, err = readTokenFile(tmpfile.Name(), "production")
+	assert.Contains(t, err.Error(), "No auth_token found for profile production")
+}
+
+func TestReadTokenFileNotFound(t *testing.T) {
+	_, err := readTokenFile("filedoesnotexist", "default")
+	assert.Contains(t, err.Error(), "Failed to open token file")
+}
+
+func TestValidateCredentialsSuccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/organization", r.URL.Path)
+		assert.Equal(t, "XXX", r.Header.Get("X-SF-Token"))
+		fmt.Fprint(w, `{"id":"ORGID"}`)
+	}))
+	defer server.Close()
+
+	err := validateCredentials(&signalformConfig{APIURL: server.URL, AuthToken: "XXX"})
+	assert.Nil(t, err)
+}
+
+func TestValidateCredentialsUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(401)
+	}))
+	defer server.Close()
+
+	err := validateCredentials(&signalformConfig{APIURL: server.URL, AuthToken: "XXX"})
+	assert.Contains(t, err.Error(), "auth_token is not valid")
+}
+
+func TestValidateMaxConcurrentRequests(t *testing.T) {
+	_, errors := validateMaxConcurrentRequests(5, "max_concurrent_requests")
+	assert.Equal(t, 0, len(errors))
+	_, errors = validateMaxConcurrentRequests(0, "max_concurrent_requests")
+	assert.Equal(t, 1, len(errors))
+}
+
+func TestValidateRetryStrategy(t *testing.T) {
+	for _, value := range []string{"exponential", "constant"} {
+		_, errors := validateRetryStrategy(value, "retry_strategy")
+		assert.Equal(t, 0, len(errors))
+	}
+	_, errors := validateRetryStrategy("linear", "retry_strategy")
+	assert.Equal(t, 1, len(errors))
+}
+
 func TestReadConfigFileFileNotFound(t *testing.T) {
 	SystemConfigPath = "filedoesnotexist"
 	HomeConfigPath = "filedoesnotexist"
@@ -0,0 +1,218 @@
+package signalform
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DATAPOINT_API_PATH = "/v2/datapoint"
+	// How often the self metrics are sent to SignalFx
+	SELF_METRICS_INTERVAL = 10 * time.Second
+	// Number of API calls recorded after which they're sent without waiting for the interval
+	SELF_METRICS_MAX_CALLS = 500
+)
+
+/*
+  The self metrics of the configured providers, sent one last time when the plugin exits
+*/
+var configuredSelfMetrics = struct {
+	sync.Mutex
+	list []*selfMetrics
+}{}
+
+type apiCallKey struct {
+	method   string
+	endpoint string
+	status   int
+}
+
+type apiCallStats struct {
+	count   int
+	errors  int
+	latency time.Duration
+}
+
+/*
+  Collects the API calls of the provider and reports them to the SignalFx ingest API as datapoints:
+  signalform.api.calls and signalform.api.errors (counters) and signalform.api.latency_ms (gauge, average
+  over the interval), by method, endpoint and status code. The datapoints are sent by the API calls
+  themselves, once the interval passed or enough of them are recorded, and by FlushSelfMetrics.
+*/
+type selfMetrics struct {
+	mutex      sync.Mutex
+	url        string
+	token      string
+	dimensions map[string]string
+	client     *http.Client
+	calls      map[apiCallKey]*apiCallStats
+	pending    int
+	flushed    time.Time
+}
+
+func newSelfMetrics(ingestUrl string, token string, dimensions map[string]string, client *http.Client) *selfMetrics {
+	return &selfMetrics{
+		url:        ingestUrl + DATAPOINT_API_PATH,
+		token:      token,
+		dimensions: dimensions,
+		client:     client,
+		calls:      map[apiCallKey]*apiCallStats{},
+		flushed:    time.Now(),
+	}
+}
+
+/*
+  Sends the self metrics of all the configured providers, for the calls recorded since they were last
+  sent. Called when the plugin exits, since the last calls of a run would be lost otherwise.
+*/
+func FlushSelfMetrics() {
+	configuredSelfMetrics.Lock()
+	list := configuredSelfMetrics.list
+	configuredSelfMetrics.list = nil
+	configuredSelfMetrics.Unlock()
+
+	for _, metrics := range list {
+		metrics.flush()
+	}
+}
+
+func registerSelfMetrics(metrics *selfMetrics) {
+	configuredSelfMetrics.Lock()
+	defer configuredSelfMetrics.Unlock()
+	configuredSelfMetrics.list = append(configuredSelfMetrics.list, metrics)
+}
+
+/*
+  Records an API call; status_code is -1 when the request didn't go through. The calls recorded so far
+  are sent once the interval passed, or after SELF_METRICS_MAX_CALLS of them.
+*/
+func (metrics *selfMetrics) record(method string, apiUrl string, status_code int, latency time.Duration) {
+	if metrics.add(method, apiUrl, status_code, latency) {
+		metrics.flush()
+	}
+}
+
+/*
+  Adds an API call to the recorded ones, and tells whether they're due to be sent
+*/
+func (metrics *selfMetrics) add(method string, apiUrl string, status_code int, latency time.Duration) bool {
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+
+	key := apiCallKey{method: method, endpoint: apiEndpoint(apiUrl), status: status_code}
+	stats, ok := metrics.calls[key]
+	if !ok {
+		stats = &apiCallStats{}
+		metrics.calls[key] = stats
+	}
+	stats.count++
+	if status_code == -1 || status_code >= 400 {
+		stats.errors++
+	}
+	stats.latency += latency
+	metrics.pending++
+
+	return metrics.pending >= SELF_METRICS_MAX_CALLS || time.Since(metrics.flushed) >= SELF_METRICS_INTERVAL
+}
+
+/*
+  Builds the datapoints of the calls recorded since the last time, and starts over
+*/
+func (metrics *selfMetrics) datapoints() map[string][]map[string]interface{} {
+	metrics.mutex.Lock()
+	calls := metrics.calls
+	metrics.calls = map[apiCallKey]*apiCallStats{}
+	metrics.pending = 0
+	metrics.flushed = time.Now()
+	metrics.mutex.Unlock()
+
+	if len(calls) == 0 {
+		return nil
+	}
+	keys := make([]apiCallKey, 0, len(calls))
+	for key := range calls {
+		keys = append(keys, key)
+	}
+	// Stable order, for the tests
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].endpoint+keys[i].method+strconv.Itoa(keys[i].status) < keys[j].endpoint+keys[j].method+strconv.Itoa(keys[j].status)
+	})
+
+	timestamp := time.Now().Unix() * 1000
+	counters := make([]map[string]interface{}, 0)
+	gauges := make([]map[string]interface{}, 0)
+	for _, key := range keys {
+		stats := calls[key]
+		dimensions := map[string]string{
+			"method":      key.method,
+			"endpoint":    key.endpoint,
+			"status_code": strconv.Itoa(key.status),
+		}
+		for name, value := range metrics.dimensions {
+			dimensions[name] = value
+		}
+		datapoint := func(metric string, value interface{}) map[string]interface{} {
+			return map[string]interface{}{
+				"metric":     metric,
+				"value":      value,
+				"dimensions": dimensions,
+				"timestamp":  timestamp,
+			}
+		}
+		counters = append(counters, datapoint("signalform.api.calls", stats.count))
+		if stats.errors > 0 {
+			counters = append(counters, datapoint("signalform.api.errors", stats.errors))
+		}
+		gauges = append(gauges, datapoint("signalform.api.latency_ms", float64(stats.latency/time.Millisecond)/float64(stats.count)))
+	}
+	return map[string][]map[string]interface{}{
+		"counter": counters,
+		"gauge":   gauges,
+	}
+}
+
+/*
+  Sends the recorded calls to SignalFx. Failures are only logged: the metrics must never fail an apply.
+*/
+func (metrics *selfMetrics) flush() {
+	datapoints := metrics.datapoints()
+	if datapoints == nil {
+		return
+	}
+	payload, err := json.Marshal(datapoints)
+	if err != nil {
+		log.Printf("[WARN] Failed creating the self metrics payload: %s", err.Error())
+		return
+	}
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/json")
+	headers.Set("X-SF-Token", metrics.token)
+	headers.Set("User-Agent", USER_AGENT)
+	// Straight to doRequest, since sendRequest would record this call too
+	status_code, _, _, err := doRequest(metrics.client, "POST", metrics.url, headers, payload)
+	if err != nil || status_code != 200 {
+		log.Printf("[WARN] Failed sending the self metrics to %s: status %d", metrics.url, status_code)
+	}
+}
+
+/*
+  Name of the endpoint of an API url (e.g. chart for https://api.signalfx.com/v2/chart/XXX)
+*/
+func apiEndpoint(apiUrl string) string {
+	parsed, err := url.Parse(apiUrl)
+	if err != nil {
+		return "unknown"
+	}
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(parts) < 2 {
+		return "unknown"
+	}
+	return parts[1]
+}
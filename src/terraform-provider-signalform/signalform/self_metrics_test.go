@@ -0,0 +1,100 @@
+package signalform
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApiEndpoint(t *testing.T) {
+	assert.Equal(t, "chart", apiEndpoint("https://api.signalfx.com/v2/chart/ABCD"))
+	assert.Equal(t, "dashboardgroup", apiEndpoint("https://api.signalfx.com/v2/dashboardgroup"))
+	assert.Equal(t, "token", apiEndpoint("https://api.signalfx.com/v2/token?name=foo"))
+	assert.Equal(t, "unknown", apiEndpoint("https://api.signalfx.com/"))
+}
+
+func TestSelfMetricsDatapoints(t *testing.T) {
+	metrics := newSelfMetrics("https://ingest.signalfx.com", "token", map[string]string{"pipeline": "ci"}, &http.Client{})
+	metrics.record("GET", "https://api.signalfx.com/v2/chart/A", 200, 10*time.Millisecond)
+	metrics.record("GET", "https://api.signalfx.com/v2/chart/B", 200, 30*time.Millisecond)
+	metrics.record("POST", "https://api.signalfx.com/v2/detector", 400, 5*time.Millisecond)
+
+	datapoints := metrics.datapoints()
+	counters := datapoints["counter"]
+	assert.Equal(t, 3, len(counters))
+	assert.Equal(t, "signalform.api.calls", counters[0]["metric"])
+	assert.Equal(t, 2, counters[0]["value"])
+	assert.Equal(t, map[string]string{"method": "GET", "endpoint": "chart", "status_code": "200", "pipeline": "ci"}, counters[0]["dimensions"])
+	assert.Equal(t, "signalform.api.errors", counters[2]["metric"])
+	assert.Equal(t, 1, counters[2]["value"])
+	gauges := datapoints["gauge"]
+	assert.Equal(t, 2, len(gauges))
+	assert.Equal(t, 20.0, gauges[0]["value"])
+
+	// The calls are reported only once
+	assert.Nil(t, metrics.datapoints())
+}
+
+func TestSelfMetricsFlush(t *testing.T) {
+	var received map[string][]map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/datapoint", r.URL.Path)
+		assert.Equal(t, "ingest-token", r.Header.Get("X-SF-Token"))
+		body, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+	}))
+	defer server.Close()
+
+	metrics := newSelfMetrics(server.URL, "ingest-token", nil, &http.Client{})
+	metrics.record("DELETE", "https://api.signalfx.com/v2/dashboard/A", -1, time.Second)
+	metrics.flush()
+	assert.Equal(t, 2, len(received["counter"]))
+	assert.Equal(t, "signalform.api.errors", received["counter"][1]["metric"])
+}
+
+func TestSelfMetricsSentByTheCalls(t *testing.T) {
+	posts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts++
+	}))
+	defer server.Close()
+
+	metrics := newSelfMetrics(server.URL, "ingest-token", nil, &http.Client{})
+	metrics.record("GET", "https://api.signalfx.com/v2/chart/A", 200, time.Millisecond)
+	assert.Equal(t, 0, posts)
+
+	// Once the interval passed
+	metrics.flushed = time.Now().Add(-SELF_METRICS_INTERVAL)
+	metrics.record("GET", "https://api.signalfx.com/v2/chart/B", 200, time.Millisecond)
+	assert.Equal(t, 1, posts)
+	assert.Nil(t, metrics.datapoints())
+
+	// Or after enough calls
+	for i := 0; i < SELF_METRICS_MAX_CALLS; i++ {
+		metrics.record("GET", "https://api.signalfx.com/v2/chart/C", 200, time.Millisecond)
+	}
+	assert.Equal(t, 2, posts)
+}
+
+func TestFlushSelfMetrics(t *testing.T) {
+	posts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts++
+	}))
+	defer server.Close()
+
+	metrics := newSelfMetrics(server.URL, "ingest-token", nil, &http.Client{})
+	registerSelfMetrics(metrics)
+	metrics.record("GET", "https://api.signalfx.com/v2/chart/A", 200, time.Millisecond)
+	FlushSelfMetrics()
+	assert.Equal(t, 1, posts)
+
+	// Sent only once
+	FlushSelfMetrics()
+	assert.Equal(t, 1, posts)
+}
@@ -0,0 +1,82 @@
+package signalform
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const SIGNALFLOW_API_PATH = "/v2/signalflow"
+
+func signalflowValidationDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"program_text": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Signalflow program text to validate. More info at \"https://developers.signalfx.com/docs/signalflow-overview\"",
+			},
+			"valid": &schema.Schema{
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the program is valid. An invalid program fails the plan, so this is always true when set",
+			},
+		},
+
+		Read: signalflowValidationRead,
+	}
+}
+
+func signalflowValidationRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	programText := sanitizeProgramText(d.Get("program_text").(string))
+
+	if err := validateSignalflowProgram(config, programText, config.AuthToken); err != nil {
+		return err
+	}
+	d.SetId(fmt.Sprintf("%d", hashcode.String(programText)))
+	d.Set("valid", true)
+
+	return nil
+}
+
+/*
+  Submits the program to the SignalFlow preflight endpoint over the last minute of data. A program that
+  doesn't parse is rejected by SignalFx with a 400 and the parser error, which is returned as is.
+*/
+func validateSignalflowProgram(config *signalformConfig, programText string, sfxToken string) error {
+	stop := time.Now().Unix() * 1000
+	url := fmt.Sprintf("%s%s/preflight?start=%d&stop=%d", config.StreamURL, apiPath(config, SIGNALFLOW_API_PATH), stop-60*1000, stop)
+	status_code, resp_body, err := sendRequestWithContentType(config, "POST", url, sfxToken, "text/plain", []byte(programText))
+	if err != nil {
+		return err
+	}
+	if status_code == 200 {
+		return nil
+	}
+
+	mapped_resp := map[string]interface{}{}
+	if err := json.Unmarshal(resp_body, &mapped_resp); err == nil {
+		if message, ok := mapped_resp["message"].(string); ok {
+			return fmt.Errorf("Invalid SignalFlow program: %s", message)
+		}
+	}
+	return fmt.Errorf("SignalFx returned status %d validating the SignalFlow program: \n%s", status_code, resp_body)
+}
+
+/*
+  Validates the program_text of a chart or detector before sending it, when validate_program_text is
+  enabled in the provider, so an invalid program fails with the error of the parser
+*/
+func checkProgramText(config *signalformConfig, d *schema.ResourceData) error {
+	if !config.ValidateProgramText || config.DryRun {
+		return nil
+	}
+	if err := validateSignalflowProgram(config, sanitizeProgramText(d.Get("program_text").(string)), config.AuthToken); err != nil {
+		return fmt.Errorf("The program_text of the resource %s is not valid: %s", d.Get("name"), err.Error())
+	}
+	return nil
+}
@@ -21,6 +21,12 @@ func singleValueChartResource() *schema.Resource {
 				Computed:    true,
 				Description: "Latest timestamp the resource was updated",
 			},
+			"drifted_fields": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Fields of the SignalFx object changed outside of Terraform, found when last refreshed",
+			},
 			"resource_url": &schema.Schema{
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -32,15 +38,17 @@ func singleValueChartResource() *schema.Resource {
 				Computed:    true,
 				Description: "URL of the chart",
 			},
+			"credential": credentialSchema(),
 			"name": &schema.Schema{
 				Type:        schema.TypeString,
 				Required:    true,
 				Description: "Name of the chart",
 			},
 			"description": &schema.Schema{
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "Description of the chart (Optional)",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDescription,
+				Description:  "Description of the chart (Optional)",
 			},
 			"program_text": &schema.Schema{
 				Type:        schema.TypeString,
@@ -48,14 +56,21 @@ func singleValueChartResource() *schema.Resource {
 				Description: "Signalflow program text for the chart. More info at \"https://developers.signalfx.com/docs/signalflow-overview\"",
 			},
 			"unit_prefix": &schema.Schema{
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "(Metric by default) Must be \"Metric\" or \"Binary\"",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateUnitPrefix,
+				Description:  "(Metric by default) Must be \"Metric\" or \"Binary\"",
 			},
 			"color_by": &schema.Schema{
-				Type:        schema.TypeString,
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateSingleValueColorBy,
+				Description:  "(Metric by default) Must be \"Metric\", \"Dimension\", or \"Scale\". \"Scale\" maps to Color by Value in the UI",
+			},
+			"minimum_resolution": &schema.Schema{
+				Type:        schema.TypeInt,
 				Optional:    true,
-				Description: "(Metric by default) Must be \"Metric\", \"Dimension\", or \"Scale\". \"Scale\" maps to Color by Value in the UI",
+				Description: "The minimum resolution (in seconds) to use for computing the underlying program",
 			},
 			"max_delay": &schema.Schema{
 				Type:         schema.TypeInt,
@@ -63,15 +78,33 @@ func singleValueChartResource() *schema.Resource {
 				Description:  "How long (in seconds) to wait for late datapoints",
 				ValidateFunc: validateMaxDelayValue,
 			},
-			"refresh_interval": &schema.Schema{
-				Type:        schema.TypeInt,
+			"timezone": &schema.Schema{
+				Type:        schema.TypeString,
 				Optional:    true,
-				Description: "How often (in seconds) to refresh the values of the list",
+				Description: "Time zone the calendar windows of the program are based on (e.g. Europe/Paris), instead of UTC",
 			},
-			"max_precision": &schema.Schema{
-				Type:        schema.TypeInt,
+			"disable_sampling": &schema.Schema{
+				Type:        schema.TypeBool,
 				Optional:    true,
-				Description: "The maximum precision to for values displayed in the list",
+				Description: "(false by default) If false, samples a subset of the output MTS, which improves UI performance",
+			},
+			"refresh_interval": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validateRefreshInterval,
+				Description:  "How often (in seconds) to refresh the value",
+			},
+			"max_precision": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validateMaxPrecision,
+				Description:  "Maximum number of digits to display when rounding the value up or down",
+			},
+			"secondary_visualization": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateSecondaryVisualization,
+				Description:  "(None by default) Gauge shown along with the value: \"None\", \"Radial\", \"Linear\" or \"Sparkline\"",
 			},
 			"is_timestamp_hidden": &schema.Schema{
 				Type:        schema.TypeBool,
@@ -135,6 +168,11 @@ func singleValueChartResource() *schema.Resource {
 							Required:    true,
 							Description: "The label used in the publish statement that displays the plot (metric time series data) you want to customize",
 						},
+						"display_name": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Name shown for the plot in the chart and its legend, instead of the label",
+						},
 						"color": &schema.Schema{
 							Type:         schema.TypeString,
 							Optional:     true,
@@ -162,10 +200,11 @@ func singleValueChartResource() *schema.Resource {
 			},
 		},
 
-		Create: singlevaluechartCreate,
-		Read:   singlevaluechartRead,
-		Update: singlevaluechartUpdate,
-		Delete: singlevaluechartDelete,
+		Create:   singlevaluechartCreate,
+		Read:     singlevaluechartRead,
+		Update:   singlevaluechartUpdate,
+		Delete:   singlevaluechartDelete,
+		Importer: chartImporter("SingleValue", getSingleValueChartFieldsFromApi),
 	}
 }
 
@@ -173,6 +212,13 @@ func singleValueChartResource() *schema.Resource {
   Use Resource object to construct json payload in order to create a single value chart
 */
 func getPayloadSingleValueChart(d *schema.ResourceData) ([]byte, error) {
+	if err := checkColorScale(d.Get("color_scale").(*schema.Set).List()); err != nil {
+		return nil, err
+	}
+	if d.Get("color_by").(string) == "Scale" && d.Get("color_scale").(*schema.Set).Len() == 0 {
+		return nil, fmt.Errorf("color_by = \"Scale\" needs at least one color_scale")
+	}
+
 	payload := map[string]interface{}{
 		"name":        d.Get("name").(string),
 		"description": d.Get("description").(string),
@@ -208,8 +254,19 @@ func getSingleValueChartOptions(d *schema.ResourceData) map[string]interface{} {
 	}
 
 	programOptions := make(map[string]interface{})
+	if val, ok := d.GetOk("minimum_resolution"); ok {
+		programOptions["minimumResolution"] = val.(int) * 1000
+	}
 	if val, ok := d.GetOk("max_delay"); ok {
 		programOptions["maxDelay"] = val.(int) * 1000
+	}
+	if val, ok := d.GetOk("timezone"); ok {
+		programOptions["timezone"] = val.(string)
+	}
+	if val, ok := d.GetOk("disable_sampling"); ok {
+		programOptions["disableSampling"] = val.(bool)
+	}
+	if len(programOptions) > 0 {
 		viz["programOptions"] = programOptions
 	}
 
@@ -219,42 +276,95 @@ func getSingleValueChartOptions(d *schema.ResourceData) map[string]interface{} {
 	if maxPrecision, ok := d.GetOk("max_precision"); ok {
 		viz["maximumPrecision"] = maxPrecision.(int)
 	}
+	if val, ok := d.GetOk("secondary_visualization"); ok {
+		viz["secondaryVisualization"] = val.(string)
+	}
 	viz["timestampHidden"] = d.Get("is_timestamp_hidden").(bool)
 	viz["showSparkLine"] = d.Get("show_spark_line").(bool)
 
 	return viz
 }
 
+/*
+  Fields of a single value chart returned by SignalFx, for its import
+*/
+func getSingleValueChartFieldsFromApi(chart map[string]interface{}) map[string]interface{} {
+	options, _ := chart["options"].(map[string]interface{})
+	fields := make(map[string]interface{})
+
+	fields["program_text"], _ = chart["programText"].(string)
+	fields["unit_prefix"], _ = options["unitPrefix"].(string)
+	fields["color_by"], _ = options["colorBy"].(string)
+	fields["color_scale"] = getColorScaleFromApi(options["colorScale"])
+	setProgramOptionsFromApi(options, fields)
+	fields["refresh_interval"] = fromApiMilliSeconds(options["refreshInterval"])
+	max_precision, _ := options["maximumPrecision"].(float64)
+	fields["max_precision"] = int(max_precision)
+	fields["secondary_visualization"], _ = options["secondaryVisualization"].(string)
+	fields["is_timestamp_hidden"], _ = options["timestampHidden"].(bool)
+	fields["show_spark_line"], _ = options["showSparkLine"].(bool)
+	fields["viz_options"] = getPerSignalVizOptionsFromApi(options)
+
+	return fields
+}
+
 func singlevaluechartCreate(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
 	payload, err := getPayloadSingleValueChart(d)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
+	if err := checkProgramText(config, d); err != nil {
+		return err
+	}
 
-	return resourceCreate(CHART_API_URL, config.AuthToken, payload, d)
+	return resourceCreate(config, apiUrl(config, CHART_API_PATH), config.AuthToken, payload, d)
 }
 
 func singlevaluechartRead(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
-	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, CHART_API_PATH), d.Id())
 
-	return resourceRead(url, config.AuthToken, d)
+	// The state as it would be sent, to tell what changed outside of Terraform. A state that can't be
+	// sent (e.g. a color scale from before its check) is always out of sync.
+	payload, err := getPayloadSingleValueChart(d)
+	if err != nil {
+		payload = nil
+	}
+
+	return resourceRead(config, url, config.AuthToken, payload, d)
 }
 
 func singlevaluechartUpdate(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
 	payload, err := getPayloadSingleValueChart(d)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
-	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
+	if d.HasChange("program_text") {
+		if err := checkProgramText(config, d); err != nil {
+			return err
+		}
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, CHART_API_PATH), d.Id())
 
-	return resourceUpdate(url, config.AuthToken, payload, d)
+	return resourceUpdate(config, url, config.AuthToken, payload, d)
 }
 
 func singlevaluechartDelete(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
-	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
-	return resourceDelete(url, config.AuthToken, d)
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, CHART_API_PATH), d.Id())
+	return chartDelete(config, url, config.AuthToken, d)
 }
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/hashicorp/terraform/helper/schema"
+	"regexp"
+	"strings"
 )
 
 func textChartResource() *schema.Resource {
@@ -20,6 +22,12 @@ func textChartResource() *schema.Resource {
 				Computed:    true,
 				Description: "Latest timestamp the resource was updated",
 			},
+			"drifted_fields": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Fields of the SignalFx object changed outside of Terraform, found when last refreshed",
+			},
 			"resource_url": &schema.Schema{
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -31,27 +39,31 @@ func textChartResource() *schema.Resource {
 				Computed:    true,
 				Description: "URL of the chart",
 			},
+			"credential": credentialSchema(),
 			"name": &schema.Schema{
 				Type:        schema.TypeString,
 				Required:    true,
 				Description: "Name of the chart",
 			},
 			"description": &schema.Schema{
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "Description of the chart (Optional)",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDescription,
+				Description:  "Description of the chart (Optional)",
 			},
 			"markdown": &schema.Schema{
 				Type:        schema.TypeString,
 				Required:    true,
+				StateFunc:   normalizeMarkdownState,
 				Description: "Markdown text to display. More info at: https://github.com/adam-p/markdown-here/wiki/Markdown-Cheatsheet",
 			},
 		},
 
-		Create: textchartCreate,
-		Read:   textchartRead,
-		Update: textchartUpdate,
-		Delete: textchartDelete,
+		Create:   textchartCreate,
+		Read:     textchartRead,
+		Update:   textchartUpdate,
+		Delete:   textchartDelete,
+		Importer: chartImporter("Text", getTextChartFieldsFromApi),
 	}
 }
 
@@ -76,42 +88,90 @@ func getTextChartOptions(d *schema.ResourceData) map[string]interface{} {
 	viz := make(map[string]interface{})
 	viz["type"] = "Text"
 	if val, ok := d.GetOk("markdown"); ok {
-		viz["markdown"] = val.(string)
+		viz["markdown"] = normalizeMarkdown(val.(string))
 	}
 
 	return viz
 }
 
+/*
+  Fields of a text chart returned by SignalFx, for its import
+*/
+func getTextChartFieldsFromApi(chart map[string]interface{}) map[string]interface{} {
+	options, _ := chart["options"].(map[string]interface{})
+	fields := make(map[string]interface{})
+
+	markdown, _ := options["markdown"].(string)
+	fields["markdown"] = normalizeMarkdown(markdown)
+
+	return fields
+}
+
 func textchartCreate(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
 	payload, err := getPayloadTextChart(d)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
 
-	return resourceCreate(CHART_API_URL, config.AuthToken, payload, d)
+	return resourceCreate(config, apiUrl(config, CHART_API_PATH), config.AuthToken, payload, d)
 }
 
 func textchartRead(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
-	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, CHART_API_PATH), d.Id())
 
-	return resourceRead(url, config.AuthToken, d)
+	// The state as it would be sent, to tell what changed outside of Terraform
+	payload, err := getPayloadTextChart(d)
+	if err != nil {
+		return fmt.Errorf("Failed creating json payload: %s", err.Error())
+	}
+
+	return resourceRead(config, url, config.AuthToken, payload, d)
 }
 
 func textchartUpdate(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
 	payload, err := getPayloadTextChart(d)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
-	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
+	url := fmt.Sprintf("%s/%s", apiUrl(config, CHART_API_PATH), d.Id())
 
-	return resourceUpdate(url, config.AuthToken, payload, d)
+	return resourceUpdate(config, url, config.AuthToken, payload, d)
 }
 
 func textchartDelete(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
-	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
-	return resourceDelete(url, config.AuthToken, d)
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, CHART_API_PATH), d.Id())
+	return chartDelete(config, url, config.AuthToken, d)
+}
+
+/*
+  Normalized form of the markdown, so heredocs and templatefile don't show up in the plan: Windows line
+  endings, lines of whitespace and the whitespace at the end are dropped. The trailing spaces of the
+  other lines are kept, being line breaks in markdown.
+*/
+func normalizeMarkdown(markdown string) string {
+	markdown = strings.Replace(markdown, "\r\n", "\n", -1)
+	markdown = strings.Replace(markdown, "\r", "\n", -1)
+	blank := regexp.MustCompile("(?m)^[ \t]+$")
+	markdown = blank.ReplaceAllString(markdown, "")
+	return strings.TrimRight(markdown, " \t\n")
+}
+
+func normalizeMarkdownState(v interface{}) string {
+	return normalizeMarkdown(v.(string))
 }
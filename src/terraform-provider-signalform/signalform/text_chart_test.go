@@ -0,0 +1,13 @@
+package signalform
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNormalizeMarkdown(t *testing.T) {
+	assert.Equal(t, "# Runbook\n\n* Restart the service", normalizeMarkdown("# Runbook\r\n  \r\n* Restart the service\r\n"))
+	assert.Equal(t, "Line one  \nLine two", normalizeMarkdown("Line one  \nLine two\n\n\t"))
+	assert.Equal(t, "    indented code", normalizeMarkdown("    indented code\n"))
+	assert.Equal(t, normalizeMarkdown("# Title\n"), normalizeMarkdownState("# Title\r\n"))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/hashicorp/terraform/helper/schema"
 	"math"
+	"regexp"
 	"strings"
 )
 
@@ -41,6 +42,12 @@ func timeChartResource() *schema.Resource {
 				Computed:    true,
 				Description: "Latest timestamp the resource was updated",
 			},
+			"drifted_fields": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Fields of the SignalFx object changed outside of Terraform, found when last refreshed",
+			},
 			"resource_url": &schema.Schema{
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -52,15 +59,17 @@ func timeChartResource() *schema.Resource {
 				Computed:    true,
 				Description: "URL of the chart",
 			},
+			"credential": credentialSchema(),
 			"name": &schema.Schema{
 				Type:        schema.TypeString,
 				Required:    true,
 				Description: "Name of the chart",
 			},
 			"description": &schema.Schema{
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "Description of the chart",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDescription,
+				Description:  "Description of the chart",
 			},
 			"program_text": &schema.Schema{
 				Type:        schema.TypeString,
@@ -68,14 +77,16 @@ func timeChartResource() *schema.Resource {
 				Description: "Signalflow program text for the chart. More info at \"https://developers.signalfx.com/docs/signalflow-overview\"",
 			},
 			"unit_prefix": &schema.Schema{
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "(Metric by default) Must be \"Metric\" or \"Binary\"",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateUnitPrefix,
+				Description:  "(Metric by default) Must be \"Metric\" or \"Binary\"",
 			},
 			"color_by": &schema.Schema{
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "(Dimension by default) Must be \"Dimension\" or \"Metric\"",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateColorBy,
+				Description:  "(Dimension by default) Must be \"Dimension\" or \"Metric\"",
 			},
 			"minimum_resolution": &schema.Schema{
 				Type:        schema.TypeInt,
@@ -88,6 +99,11 @@ func timeChartResource() *schema.Resource {
 				Description:  "How long (in seconds) to wait for late datapoints",
 				ValidateFunc: validateMaxDelayValue,
 			},
+			"timezone": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Time zone the calendar windows of the program are based on (e.g. Europe/Paris), instead of UTC",
+			},
 			"disable_sampling": &schema.Schema{
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -113,8 +129,10 @@ func timeChartResource() *schema.Resource {
 				ConflictsWith: []string{"time_range"},
 			},
 			"axis_right": &schema.Schema{
-				Type:     schema.TypeSet,
-				Optional: true,
+				Type:        schema.TypeSet,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Options of the right Y-axis",
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"min_value": &schema.Schema{
@@ -160,8 +178,10 @@ func timeChartResource() *schema.Resource {
 				},
 			},
 			"axis_left": &schema.Schema{
-				Type:     schema.TypeSet,
-				Optional: true,
+				Type:        schema.TypeSet,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Options of the left Y-axis",
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"min_value": &schema.Schema{
@@ -222,22 +242,85 @@ func timeChartResource() *schema.Resource {
 				Description: "Dimension to show in the on-chart legend. On-chart legend is off unless a dimension is specified. Allowed: 'metric', 'plot_label' and any dimension.",
 			},
 			"legend_fields_to_hide": &schema.Schema{
-				Type:        schema.TypeSet,
-				Optional:    true,
-				Elem:        &schema.Schema{Type: schema.TypeString},
-				Description: "List of properties that shouldn't be displayed in the chart legend (i.e. dimension names)",
+				Type:          schema.TypeSet,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				Description:   "List of properties that shouldn't be displayed in the chart legend (i.e. dimension names)",
+				ConflictsWith: []string{"legend_options_fields"},
 			},
+			"legend_options_fields": legendOptionsFieldsSchema(),
 			"show_event_lines": &schema.Schema{
 				Type:        schema.TypeBool,
 				Optional:    true,
 				Description: "(false by default) Whether vertical highlight lines should be drawn in the visualizations at times when events occurred",
 			},
+			"event_options": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Options of the events shown on the chart, associated with a publish statement of events (e.g. events(eventType='deploy').publish(label='D'))",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"label": &schema.Schema{
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The label used in the publish statement of the events",
+						},
+						"display_name": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Name shown for the events in the chart, instead of the label",
+						},
+						"color": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Color of the events",
+							ValidateFunc: validatePerSignalColor,
+						},
+					},
+				},
+			},
+			"linked_detector": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Detectors whose alerts are shown on the chart, as event markers",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"detector_id": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validateLinkedDetectorId,
+							Description:  "ID of the detector",
+						},
+						"label": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "(ID of the detector by default) Label the alerts are published with, to customize them in event_options",
+						},
+					},
+				},
+			},
 			"show_data_markers": &schema.Schema{
 				Type:        schema.TypeBool,
 				Optional:    true,
 				Default:     false,
 				Description: "(false by default) Show markers (circles) for each datapoint used to draw line or area charts",
 			},
+			"histogram_options": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Options of the histogram, when plot_type is Histogram",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"color_theme": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validatePerSignalColor,
+							Description:  "Color palette of the histogram. Must be one of the colors of viz_options",
+						},
+					},
+				},
+			},
 			"stacked": &schema.Schema{
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -267,6 +350,11 @@ func timeChartResource() *schema.Resource {
 							Required:    true,
 							Description: "The label used in the publish statement that displays the plot (metric time series data) you want to customize",
 						},
+						"display_name": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Name shown for the plot in the chart and its legend, instead of the label",
+						},
 						"color": &schema.Schema{
 							Type:         schema.TypeString,
 							Optional:     true,
@@ -306,10 +394,11 @@ func timeChartResource() *schema.Resource {
 			},
 		},
 
-		Create: timechartCreate,
-		Read:   timechartRead,
-		Update: timechartUpdate,
-		Delete: timechartDelete,
+		Create:   timechartCreate,
+		Read:     timechartRead,
+		Update:   timechartUpdate,
+		Delete:   timechartDelete,
+		Importer: chartImporter("TimeSeriesChart", getTimeChartFieldsFromApi),
 	}
 }
 
@@ -317,10 +406,14 @@ func timeChartResource() *schema.Resource {
   Use Resource object to construct json payload in order to create a time chart
 */
 func getPayloadTimeChart(d *schema.ResourceData) ([]byte, error) {
+	if err := checkTimeSpan(d); err != nil {
+		return nil, err
+	}
+
 	payload := map[string]interface{}{
 		"name":        d.Get("name").(string),
 		"description": d.Get("description").(string),
-		"programText": sanitizeProgramText(d.Get("program_text").(string)),
+		"programText": sanitizeProgramText(d.Get("program_text").(string)) + getLinkedDetectorsProgramText(d),
 	}
 
 	viz := getTimeChartOptions(d)
@@ -333,6 +426,9 @@ func getPayloadTimeChart(d *schema.ResourceData) ([]byte, error) {
 	if vizOptions := getPerSignalVizOptions(d); len(vizOptions) > 0 {
 		viz["publishLabelOptions"] = vizOptions
 	}
+	if eventOptions := getEventOptions(d); len(eventOptions) > 0 {
+		viz["eventPublishLabelOptions"] = eventOptions
+	}
 	if onChartLegendDim, ok := d.GetOk("on_chart_legend_dimension"); ok {
 		if onChartLegendDim == "metric" {
 			onChartLegendDim = "sf_originatingMetric"
@@ -366,6 +462,9 @@ func getPerSignalVizOptions(d *schema.ResourceData) []map[string]interface{} {
 		item := make(map[string]interface{})
 
 		item["label"] = v["label"].(string)
+		if val, ok := v["display_name"].(string); ok && val != "" {
+			item["displayName"] = val
+		}
 		if val, ok := v["color"].(string); ok {
 			if elem, ok := PaletteColors[val]; ok {
 				item["paletteIndex"] = elem
@@ -396,6 +495,73 @@ func getPerSignalVizOptions(d *schema.ResourceData) []map[string]interface{} {
 	return viz_list
 }
 
+func getEventOptions(d *schema.ResourceData) []map[string]interface{} {
+	events := d.Get("event_options").([]interface{})
+	events_list := make([]map[string]interface{}, len(events))
+	for i, event := range events {
+		event := event.(map[string]interface{})
+		item := make(map[string]interface{})
+
+		item["label"] = event["label"].(string)
+		if val, ok := event["display_name"].(string); ok && val != "" {
+			item["displayName"] = val
+		}
+		if val, ok := event["color"].(string); ok {
+			if elem, ok := PaletteColors[val]; ok {
+				item["paletteIndex"] = elem
+			}
+		}
+
+		events_list[i] = item
+	}
+	return events_list
+}
+
+/*
+  SignalFlow of the linked detectors, added to the program of the chart: it publishes the alerts of each
+  detector, which the chart shows as event markers
+*/
+func getLinkedDetectorsProgramText(d *schema.ResourceData) string {
+	text := ""
+	for _, detector := range d.Get("linked_detector").([]interface{}) {
+		detector := detector.(map[string]interface{})
+		text += getLinkedDetectorProgramText(detector["detector_id"].(string), detector["label"].(string))
+	}
+	return text
+}
+
+func getLinkedDetectorProgramText(detector_id string, label string) string {
+	if label == "" {
+		label = detector_id
+	}
+	label = strings.Replace(strings.Replace(label, "\\", "\\\\", -1), "'", "\\'", -1)
+	return fmt.Sprintf("\nalerts(detector_id='%s').publish(label='%s')", detector_id, label)
+}
+
+/*
+  Splits the program of a time chart returned by SignalFx into the one of the configuration and its
+  linked detectors, the inverse of getLinkedDetectorsProgramText
+*/
+func getLinkedDetectorsFromApi(programText string) (string, []interface{}) {
+	r := regexp.MustCompile(`\nalerts\(detector_id='([A-Za-z0-9_-]+)'\)\.publish\(label='((?:[^'\\]|\\.)*)'\)$`)
+	unescape := regexp.MustCompile(`\\(.)`)
+	detectors := make([]interface{}, 0)
+	for {
+		match := r.FindStringSubmatchIndex(programText)
+		if match == nil {
+			break
+		}
+		detector_id := programText[match[2]:match[3]]
+		label := unescape.ReplaceAllString(programText[match[4]:match[5]], "$1")
+		if label == detector_id {
+			label = ""
+		}
+		detectors = append([]interface{}{map[string]interface{}{"detector_id": detector_id, "label": label}}, detectors...)
+		programText = programText[:match[0]]
+	}
+	return programText, detectors
+}
+
 func getAxesOptions(d *schema.ResourceData) []map[string]interface{} {
 	axes_list_opts := make([]map[string]interface{}, 2)
 	if tf_axis_opts, ok := d.GetOk("axis_right"); ok {
@@ -484,6 +650,9 @@ func getTimeChartOptions(d *schema.ResourceData) map[string]interface{} {
 	if val, ok := d.GetOk("max_delay"); ok {
 		programOptions["maxDelay"] = val.(int) * 1000
 	}
+	if val, ok := d.GetOk("timezone"); ok {
+		programOptions["timezone"] = val.(string)
+	}
 	if val, ok := d.GetOk("disable_sampling"); ok {
 		programOptions["disableSampling"] = val.(bool)
 	}
@@ -491,21 +660,7 @@ func getTimeChartOptions(d *schema.ResourceData) map[string]interface{} {
 		viz["programOptions"] = programOptions
 	}
 
-	timeMap := make(map[string]interface{})
-	if val, ok := d.GetOk("time_range"); ok {
-		if ms, err := fromRangeToMilliSeconds(val.(string)); err == nil {
-			timeMap["range"] = ms
-			timeMap["type"] = "relative"
-		}
-	}
-	if val, ok := d.GetOk("start_time"); ok {
-		timeMap["start"] = val.(int) * 1000
-		timeMap["type"] = "absolute"
-		if val, ok := d.GetOk("end_time"); ok {
-			timeMap["end"] = val.(int) * 1000
-		}
-	}
-	if len(timeMap) > 0 {
+	if timeMap := getChartTimeOptions(d); len(timeMap) > 0 {
 		viz["time"] = timeMap
 	}
 
@@ -522,41 +677,215 @@ func getTimeChartOptions(d *schema.ResourceData) map[string]interface{} {
 		viz["lineChartOptions"] = dataMarkersOption
 	}
 
+	if histogramOptions, ok := d.GetOk("histogram_options"); ok {
+		histogramOptions, _ := histogramOptions.([]interface{})[0].(map[string]interface{})
+		if colorTheme, ok := histogramOptions["color_theme"].(string); ok && colorTheme != "" {
+			viz["histogramChartOptions"] = map[string]interface{}{
+				"colorThemeIndex": PaletteColors[colorTheme],
+			}
+		}
+	}
+
 	return viz
 }
 
+/*
+  Fields of a time chart returned by SignalFx, for its import
+*/
+func getTimeChartFieldsFromApi(chart map[string]interface{}) map[string]interface{} {
+	options, _ := chart["options"].(map[string]interface{})
+	fields := make(map[string]interface{})
+
+	programText, _ := chart["programText"].(string)
+	fields["program_text"], fields["linked_detector"] = getLinkedDetectorsFromApi(programText)
+	fields["tags"] = getApiStrings(chart["tags"])
+	fields["unit_prefix"], _ = options["unitPrefix"].(string)
+	fields["color_by"], _ = options["colorBy"].(string)
+	fields["show_event_lines"], _ = options["showEventLines"].(bool)
+	fields["stacked"], _ = options["stacked"].(bool)
+	plot_type, _ := options["defaultPlotType"].(string)
+	fields["plot_type"] = plot_type
+	axis_precision, _ := options["axisPrecision"].(float64)
+	fields["axes_precision"] = int(axis_precision)
+	fields["axes_include_zero"], _ = options["includeZero"].(bool)
+	setProgramOptionsFromApi(options, fields)
+
+	fields["time_range"], fields["start_time"], fields["end_time"] = getChartTimeFromApi(options["time"])
+
+	dataMarkersOption, _ := options["lineChartOptions"].(map[string]interface{})
+	if plot_type == "AreaChart" {
+		dataMarkersOption, _ = options["areaChartOptions"].(map[string]interface{})
+	}
+	fields["show_data_markers"], _ = dataMarkersOption["showDataMarkers"].(bool)
+
+	fields["histogram_options"] = []interface{}{}
+	histogramOptions, _ := options["histogramChartOptions"].(map[string]interface{})
+	if colorTheme := getPaletteColorName(histogramOptions["colorThemeIndex"]); colorTheme != "" {
+		fields["histogram_options"] = []interface{}{map[string]interface{}{"color_theme": colorTheme}}
+	}
+
+	axes, _ := options["axes"].([]interface{})
+	fields["axis_left"], fields["axis_right"] = []interface{}{}, []interface{}{}
+	for i, field := range []string{"axis_left", "axis_right"} {
+		if i < len(axes) {
+			if axis := getSingleAxisOptionsFromApi(axes[i]); axis != nil {
+				fields[field] = []interface{}{axis}
+			}
+		}
+	}
+
+	fields["legend_fields_to_hide"] = []interface{}{}
+	fields["legend_options_fields"] = getLegendOptionsFieldsFromApi(options)
+	fields["on_chart_legend_dimension"] = ""
+	if onChartLegend, ok := options["onChartLegendOptions"].(map[string]interface{}); ok && onChartLegend["showLegend"] == true {
+		dimension, _ := onChartLegend["dimensionInLegend"].(string)
+		fields["on_chart_legend_dimension"] = getLegendPropertyFromApi(dimension)
+	}
+
+	api_viz, _ := options["publishLabelOptions"].([]interface{})
+	viz := make([]interface{}, 0)
+	for _, option := range api_viz {
+		option, ok := option.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		item := getVizOptionFromApi(option)
+		item["plot_type"], _ = option["plotType"].(string)
+		item["axis"] = ""
+		if y_axis, _ := option["yAxis"].(float64); y_axis == 1 {
+			item["axis"] = "right"
+		}
+		viz = append(viz, item)
+	}
+	fields["viz_options"] = viz
+
+	api_events, _ := options["eventPublishLabelOptions"].([]interface{})
+	events := make([]interface{}, 0)
+	for _, event := range api_events {
+		event, ok := event.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		item := make(map[string]interface{})
+		item["label"], _ = event["label"].(string)
+		item["display_name"], _ = event["displayName"].(string)
+		item["color"] = getPaletteColorName(event["paletteIndex"])
+		events = append(events, item)
+	}
+	fields["event_options"] = events
+
+	return fields
+}
+
+/*
+  Options of an axis returned by SignalFx, with the defaults of the schema for what isn't set; nil when
+  nothing is
+*/
+func getSingleAxisOptionsFromApi(value interface{}) map[string]interface{} {
+	axis, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	item := make(map[string]interface{})
+	empty := true
+
+	for field, api_field := range map[string]string{"min_value": "min", "low_watermark": "lowWatermark"} {
+		item[field] = float64(-math.MaxFloat32)
+		if value, ok := axis[api_field].(float64); ok {
+			item[field] = value
+			empty = false
+		}
+	}
+	for field, api_field := range map[string]string{"max_value": "max", "high_watermark": "highWatermark"} {
+		item[field] = float64(math.MaxFloat32)
+		if value, ok := axis[api_field].(float64); ok {
+			item[field] = value
+			empty = false
+		}
+	}
+	for field, api_field := range map[string]string{"label": "label", "high_watermark_label": "highWatermarkLabel", "low_watermark_label": "lowWatermarkLabel"} {
+		item[field], _ = axis[api_field].(string)
+		if item[field] != "" {
+			empty = false
+		}
+	}
+
+	if empty {
+		return nil
+	}
+	return item
+}
+
 func timechartCreate(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
 	payload, err := getPayloadTimeChart(d)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
+	if err := checkProgramText(config, d); err != nil {
+		return err
+	}
 
-	return resourceCreate(CHART_API_URL, config.AuthToken, payload, d)
+	return resourceCreate(config, apiUrl(config, CHART_API_PATH), config.AuthToken, payload, d)
 }
 
 func timechartRead(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
-	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, CHART_API_PATH), d.Id())
 
-	return resourceRead(url, config.AuthToken, d)
+	// The state as it would be sent, to tell what changed outside of Terraform. A state that can't be
+	// sent (e.g. a time span from before its check) is always out of sync.
+	payload, err := getPayloadTimeChart(d)
+	if err != nil {
+		payload = nil
+	}
+
+	return resourceRead(config, url, config.AuthToken, payload, d)
 }
 
 func timechartUpdate(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
 	payload, err := getPayloadTimeChart(d)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
-	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
+	if d.HasChange("program_text") {
+		if err := checkProgramText(config, d); err != nil {
+			return err
+		}
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, CHART_API_PATH), d.Id())
 
-	return resourceUpdate(url, config.AuthToken, payload, d)
+	return resourceUpdate(config, url, config.AuthToken, payload, d)
 }
 
 func timechartDelete(d *schema.ResourceData, meta interface{}) error {
-	config := meta.(*signalformConfig)
-	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
-	return resourceDelete(url, config.AuthToken, d)
+	config, err := resourceConfig(d, meta)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/%s", apiUrl(config, CHART_API_PATH), d.Id())
+	return chartDelete(config, url, config.AuthToken, d)
+}
+
+/*
+  Validates the ID of a linked detector, which ends up in the program text of the chart
+*/
+func validateLinkedDetectorId(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	if !regexp.MustCompile("^[A-Za-z0-9_-]+$").MatchString(value) {
+		errors = append(errors, fmt.Errorf("%s not allowed; must be the ID of a detector", value))
+	}
+	return
 }
 
 /*
@@ -2,6 +2,7 @@ package signalform
 
 import (
 	"github.com/stretchr/testify/assert"
+	"math"
 	"testing"
 )
 
@@ -16,3 +17,95 @@ func TestValidatePlotTypeTimeChartNotAllowed(t *testing.T) {
 	_, errors := validatePlotTypeTimeChart("absolute", "plot_type")
 	assert.Equal(t, len(errors), 1)
 }
+
+func TestGetTimeChartFieldsFromApi(t *testing.T) {
+	fields := getTimeChartFieldsFromApi(map[string]interface{}{
+		"programText": "A = data('cpu.utilization').publish(label='A')",
+		"tags":        []interface{}{"team:infra"},
+		"options": map[string]interface{}{
+			"type":            "TimeSeriesChart",
+			"defaultPlotType": "AreaChart",
+			"stacked":         true,
+			"time":            map[string]interface{}{"type": "relative", "range": float64(900000)},
+			"areaChartOptions": map[string]interface{}{
+				"showDataMarkers": true,
+			},
+			"axes": []interface{}{
+				map[string]interface{}{"label": "CPU", "min": float64(0), "max": nil},
+				map[string]interface{}{"label": "", "min": nil, "max": nil},
+			},
+			"onChartLegendOptions": map[string]interface{}{"showLegend": true, "dimensionInLegend": "sf_metric"},
+			"publishLabelOptions": []interface{}{
+				map[string]interface{}{"label": "A", "plotType": "ColumnChart", "yAxis": float64(1), "paletteIndex": float64(14)},
+			},
+			"eventPublishLabelOptions": []interface{}{
+				map[string]interface{}{"label": "D", "displayName": "Deploys"},
+			},
+		},
+	})
+	assert.Equal(t, "A = data('cpu.utilization').publish(label='A')", fields["program_text"])
+	assert.Equal(t, []interface{}{"team:infra"}, fields["tags"])
+	assert.Equal(t, "AreaChart", fields["plot_type"])
+	assert.Equal(t, true, fields["stacked"])
+	assert.Equal(t, true, fields["show_data_markers"])
+	assert.Equal(t, "-15m", fields["time_range"])
+	assert.Equal(t, 0, fields["start_time"])
+	assert.Equal(t, "plot_label", fields["on_chart_legend_dimension"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"min_value":            float64(0),
+			"max_value":            float64(math.MaxFloat32),
+			"label":                "CPU",
+			"high_watermark":       float64(math.MaxFloat32),
+			"high_watermark_label": "",
+			"low_watermark":        float64(-math.MaxFloat32),
+			"low_watermark_label":  "",
+		},
+	}, fields["axis_left"])
+	// An axis with nothing set isn't a block
+	assert.Equal(t, []interface{}{}, fields["axis_right"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"label":        "A",
+			"display_name": "",
+			"color":        "green",
+			"plot_type":    "ColumnChart",
+			"axis":         "right",
+			"value_unit":   "",
+			"value_prefix": "",
+			"value_suffix": "",
+		},
+	}, fields["viz_options"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"label": "D", "display_name": "Deploys", "color": ""},
+	}, fields["event_options"])
+	assert.Equal(t, []interface{}{}, fields["histogram_options"])
+}
+
+func TestGetLinkedDetectorProgramText(t *testing.T) {
+	assert.Equal(t, "\nalerts(detector_id='DETID').publish(label='DETID')", getLinkedDetectorProgramText("DETID", ""))
+	assert.Equal(t, "\nalerts(detector_id='DETID').publish(label='CPU\\'s alerts')", getLinkedDetectorProgramText("DETID", "CPU's alerts"))
+}
+
+func TestGetLinkedDetectorsFromApi(t *testing.T) {
+	program := "A = data('cpu.utilization').publish(label='A')"
+	linked := program + getLinkedDetectorProgramText("DET1", "") + getLinkedDetectorProgramText("DET2", "CPU's \\alerts")
+	programText, detectors := getLinkedDetectorsFromApi(linked)
+	assert.Equal(t, program, programText)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"detector_id": "DET1", "label": ""},
+		map[string]interface{}{"detector_id": "DET2", "label": "CPU's \\alerts"},
+	}, detectors)
+
+	// Alerts published by the program itself stay in it
+	programText, detectors = getLinkedDetectorsFromApi("alerts(detector_id='DET1').publish(label='X')")
+	assert.Equal(t, "alerts(detector_id='DET1').publish(label='X')", programText)
+	assert.Equal(t, []interface{}{}, detectors)
+}
+
+func TestValidateLinkedDetectorId(t *testing.T) {
+	_, errors := validateLinkedDetectorId("DfLU4cfAgAA", "detector_id")
+	assert.Equal(t, 0, len(errors))
+	_, errors = validateLinkedDetectorId("x').publish()", "detector_id")
+	assert.Equal(t, 1, len(errors))
+}
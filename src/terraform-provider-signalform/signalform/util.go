@@ -5,22 +5,41 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"log"
 	"math"
+	"math/rand"
 	"net/http"
+	"net/url"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/hashicorp/terraform/helper/hashcode"
 	"github.com/hashicorp/terraform/helper/schema"
 )
 
 const (
 	// Workaround for Signalfx bug related to post processing and lastUpdatedTime
-	OFFSET        = 10000.0
-	CHART_API_URL = "https://api.signalfx.com/v2/chart"
-	CHART_URL     = "https://app.signalfx.com/#/chart/<id>"
+	OFFSET         = 10000.0
+	CHART_API_PATH = "/v2/chart"
+	CHART_URL      = DEFAULT_APP_URL + "/#/chart/<id>"
+	// Number of results fetched per request when listing objects
+	PAGE_LIMIT = 100
+	USER_AGENT = "terraform-provider-signalform"
+	// Longest description accepted by the API
+	DESCRIPTION_MAX_LENGTH = 1024
+	// Prefix of the IDs of the resources created with dry_run
+	DRY_RUN_ID_PREFIX = "dry-run-"
 )
 
+// Fields of the objects set by SignalFx, which are never sent nor compared
+var ApiReadOnlyFields = []string{"id", "created", "creator", "lastUpdated", "lastUpdatedBy"}
+
 type chartColor struct {
 	name string
 	hex  string
@@ -53,26 +72,457 @@ var ChartColorsSlice = []chartColor{
 /*
   Utility function that wraps http calls to SignalFx
 */
-func sendRequest(method string, url string, token string, payload []byte) (int, []byte, error) {
-	client := &http.Client{}
+func sendRequest(config *signalformConfig, method string, url string, token string, payload []byte) (int, []byte, error) {
+	return sendRequestWithContentType(config, method, url, token, "application/json", payload)
+}
+
+/*
+  Sends the request, retrying up to config.MaxRetries times on network errors and on the statuses
+  SignalFx returns while unavailable (see isRetryable), waiting longer after each attempt.
+*/
+func sendRequestWithContentType(config *signalformConfig, method string, url string, token string, contentType string, payload []byte) (int, []byte, error) {
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{}
+	}
+	for attempt := 0; ; attempt++ {
+		if config.RateLimiter != nil {
+			config.RateLimiter.wait()
+		}
+		// Slots are held only while the request is in flight, not while waiting to retry
+		if config.RequestSlots != nil {
+			config.RequestSlots <- struct{}{}
+		}
+		start := time.Now()
+		status_code, body, respHeaders, err := doRequest(client, method, url, requestHeaders(config, token, contentType), payload)
+		if config.RequestSlots != nil {
+			<-config.RequestSlots
+		}
+		if config.SelfMetrics != nil {
+			config.SelfMetrics.record(method, url, status_code, time.Since(start))
+		}
+		if attempt >= config.MaxRetries || !isRetryable(method, status_code, err) {
+			return status_code, body, err
+		}
+		wait := retryWait(config, attempt)
+		if status_code == 429 {
+			// SignalFx tells how long to back off when rate limiting
+			if retryAfter, ok := parseRetryAfter(respHeaders.Get("Retry-After"), time.Now()); ok {
+				wait = retryAfter
+				// A far away Retry-After would hang the apply, the wait stays below retry_wait_max_seconds
+				if wait > config.RetryWaitMax {
+					log.Printf("[INFO] %s %s asked to retry in %s, waiting the retry_wait_max_seconds of %s instead", method, url, wait, config.RetryWaitMax)
+					wait = config.RetryWaitMax
+				}
+			}
+		}
+		log.Printf("[DEBUG] %s %s failed (status %d), retrying in %s", method, url, status_code, wait)
+		time.Sleep(wait)
+	}
+}
 
+/*
+  Sends a single request. Requests and responses are logged, and shown by terraform with TF_LOG=DEBUG;
+  the payloads are only logged with TF_LOG=TRACE, since they can be big.
+*/
+func doRequest(client *http.Client, method string, url string, headers http.Header, payload []byte) (int, []byte, http.Header, error) {
 	req, err := http.NewRequest(method, url, bytes.NewReader(payload))
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("X-SF-Token", token)
+	if err != nil {
+		return -1, nil, nil, &requestError{fmt.Sprintf("Failed creating %s request to %s: %s", method, url, err.Error())}
+	}
+	for name, values := range headers {
+		req.Header[name] = values
+	}
 
+	log.Printf("[DEBUG] SignalFx request: %s %s (token %s)", method, url, redactToken(headers.Get("X-SF-Token")))
+	// The session payload holds the user password
+	if len(payload) > 0 && !strings.HasSuffix(url, "/session") {
+		log.Printf("[TRACE] SignalFx request payload: %s", payload)
+	}
+	start := time.Now()
 	resp, err := client.Do(req)
 	if err != nil {
-		return -1, nil, fmt.Errorf("Failed sending %s request to Signalfx: %s", method, err.Error())
+		log.Printf("[DEBUG] SignalFx request failed: %s %s after %s: %s", method, url, time.Since(start), err.Error())
+		return -1, nil, nil, fmt.Errorf("Failed sending %s request to Signalfx: %s", method, err.Error())
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	defer resp.Body.Close()
+	log.Printf("[DEBUG] SignalFx response: %s %s returned %d in %s", method, url, resp.StatusCode, time.Since(start))
 
 	if err != nil {
-		return resp.StatusCode, nil, fmt.Errorf("Failed reading response body from %s request: %s", method, err.Error())
+		return resp.StatusCode, nil, resp.Header, fmt.Errorf("Failed reading response body from %s request: %s", method, err.Error())
+	}
+	// Successful responses can contain secrets (e.g. org tokens), the errors are what's worth looking at
+	if resp.StatusCode >= 400 {
+		log.Printf("[TRACE] SignalFx response body: %s", body)
 	}
 
-	return resp.StatusCode, body, nil
+	return resp.StatusCode, body, resp.Header, nil
+}
+
+/*
+  Headers sent with every request to SignalFx
+*/
+func requestHeaders(config *signalformConfig, token string, contentType string) http.Header {
+	headers := http.Header{}
+	// The headers of the provider can't override the ones the API relies on
+	for name, value := range config.CustomHeaders {
+		headers.Set(name, value)
+	}
+	headers.Set("Content-Type", contentType)
+	headers.Set("X-SF-Token", token)
+	userAgent := USER_AGENT
+	if config.UserAgentSuffix != "" {
+		userAgent = fmt.Sprintf("%s %s", USER_AGENT, config.UserAgentSuffix)
+	}
+	headers.Set("User-Agent", userAgent)
+	return headers
+}
+
+/*
+  Hides all but the last 4 characters of a token, enough to tell which one was used
+*/
+func redactToken(token string) string {
+	if len(token) <= 8 {
+		return "****"
+	}
+	return "****" + token[len(token)-4:]
+}
+
+/*
+  The request couldn't be built (e.g. a malformed URL): it was never sent, and sending it again fails the same way
+*/
+type requestError struct {
+	message string
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+/*
+  Tells whether a failed request is worth sending again: SignalFx is rate limiting or is temporarily
+  unavailable, so the request wasn't processed. The other failures (network errors, 502 and 504) can
+  happen after SignalFx processed the request, so only the requests safe to repeat are retried then:
+  sending a POST again could create a second object.
+*/
+func isRetryable(method string, status_code int, err error) bool {
+	if _, ok := err.(*requestError); ok {
+		return false
+	}
+	if err == nil && (status_code == 429 || status_code == 503) {
+		return true
+	}
+	if method == "POST" {
+		return false
+	}
+	return err != nil || status_code == 502 || status_code == 504
+}
+
+/*
+  Parses the Retry-After header, which is either a number of seconds or a HTTP date
+*/
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if date.Before(now) {
+			return 0, true
+		}
+		return date.Sub(now), true
+	}
+	return 0, false
+}
+
+/*
+  Backoff: waits RetryWaitMin after the first attempt, doubling up to RetryWaitMax with the exponential
+  strategy. With jitter, the wait is picked at random in its upper half, so that the concurrent requests
+  of the resources don't all retry at once.
+*/
+func retryWait(config *signalformConfig, attempt int) time.Duration {
+	wait := config.RetryWaitMin
+	if config.RetryStrategy != "constant" {
+		for i := 0; i < attempt && wait < config.RetryWaitMax; i++ {
+			wait *= 2
+		}
+	}
+	if wait > config.RetryWaitMax {
+		wait = config.RetryWaitMax
+	}
+	if config.RetryJitter && wait > 1 {
+		wait = wait/2 + time.Duration(rand.Int63n(int64(wait/2)))
+	}
+	return wait
+}
+
+/*
+  Spaces out the API calls of all the resources, so that no more than the configured
+  number of requests per second are sent to SignalFx
+*/
+type rateLimiter struct {
+	mutex    sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(requestsPerSecond float64) *rateLimiter {
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / requestsPerSecond)}
+}
+
+/*
+  Blocks until the caller is allowed to send the next request
+*/
+func (limiter *rateLimiter) wait() {
+	limiter.mutex.Lock()
+	now := time.Now()
+	if limiter.next.Before(now) {
+		limiter.next = now
+	}
+	sleep := limiter.next.Sub(now)
+	limiter.next = limiter.next.Add(limiter.interval)
+	limiter.mutex.Unlock()
+
+	time.Sleep(sleep)
+}
+
+/*
+  Builds the url of an API endpoint, e.g. /v2/chart
+*/
+func apiUrl(config *signalformConfig, path string) string {
+	return config.APIURL + apiPath(config, path)
+}
+
+/*
+  Swaps the version of the endpoint path for the one configured in the provider, if any.
+  A version set for the endpoint (e.g. "dashboard") wins over the one set for the whole API.
+*/
+func apiPath(config *signalformConfig, path string) string {
+	parts := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 3)
+	if len(parts) < 2 {
+		return path
+	}
+	version := config.APIVersion
+	if endpointVersion, ok := config.APIVersions[parts[1]]; ok {
+		version = endpointVersion
+	}
+	if version == "" {
+		return path
+	}
+	parts[0] = version
+	return "/" + strings.Join(parts, "/")
+}
+
+/*
+  Sends a GET to SignalFx and decodes the JSON response. Used by data sources.
+*/
+func getApiResource(config *signalformConfig, resourceUrl string, sfxToken string, response interface{}) error {
+	status_code, resp_body, err := sendRequest(config, "GET", resourceUrl, sfxToken, nil)
+	if err != nil {
+		return err
+	}
+	if status_code != 200 {
+		return fmt.Errorf("For %s SignalFx returned status %d: \n%s", resourceUrl, status_code, resp_body)
+	}
+	err = json.Unmarshal(resp_body, response)
+	if err != nil {
+		return fmt.Errorf("Failed unmarshaling the response for %s: %s", resourceUrl, err.Error())
+	}
+	return nil
+}
+
+/*
+  Pages through a SignalFx list endpoint (e.g. /v2/token) and returns all the results
+*/
+func getApiResults(config *signalformConfig, listUrl string, sfxToken string) ([]map[string]interface{}, error) {
+	results := make([]map[string]interface{}, 0)
+	for {
+		pageUrl, err := url.Parse(listUrl)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing url %s: %s", listUrl, err.Error())
+		}
+		query := pageUrl.Query()
+		query.Set("offset", strconv.Itoa(len(results)))
+		query.Set("limit", strconv.Itoa(PAGE_LIMIT))
+		pageUrl.RawQuery = query.Encode()
+
+		page := struct {
+			Count   int                      `json:"count"`
+			Results []map[string]interface{} `json:"results"`
+		}{}
+		if err := getApiResource(config, pageUrl.String(), sfxToken, &page); err != nil {
+			return nil, err
+		}
+		results = append(results, page.Results...)
+		if len(page.Results) == 0 || len(results) >= page.Count {
+			return results, nil
+		}
+	}
+}
+
+/*
+  Converts a JSON object decoded from the API into a map of strings, as expected by TypeMap fields
+*/
+func getStringMap(value interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	if object, ok := value.(map[string]interface{}); ok {
+		for k, v := range object {
+			result[k] = fmt.Sprintf("%v", v)
+		}
+	}
+	return result
+}
+
+/*
+  Converts a list of strings decoded from the API into the items of a TypeSet or TypeList field. SignalFx
+  sometimes returns a single string instead of a list, and an empty string for none.
+*/
+func getApiStrings(value interface{}) []interface{} {
+	result := make([]interface{}, 0)
+	switch value := value.(type) {
+	case []interface{}:
+		for _, item := range value {
+			if item, ok := item.(string); ok {
+				result = append(result, item)
+			}
+		}
+	case string:
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+/*
+  Builds the authorizedWriters object of the resources that support write protection, from the
+  authorized_writer_teams and authorized_writer_users fields. nil when none is set, so anyone can edit.
+*/
+func getAuthorizedWriters(d *schema.ResourceData) map[string]interface{} {
+	teams := d.Get("authorized_writer_teams").(*schema.Set).List()
+	users := d.Get("authorized_writer_users").(*schema.Set).List()
+	if len(teams) == 0 && len(users) == 0 {
+		return nil
+	}
+	return map[string]interface{}{
+		"teams": teams,
+		"users": users,
+	}
+}
+
+/*
+  Length in milliseconds of a SignalFx relative time (e.g. -1h), false if it isn't one
+*/
+func relativeTimeMillis(ts string) (int64, bool) {
+	r := regexp.MustCompile("^-([0-9]+)([smhdw])$")
+	matches := r.FindStringSubmatch(ts)
+	if matches == nil {
+		return 0, false
+	}
+	value, err := strconv.ParseInt(matches[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	units := map[string]int64{"s": 1000, "m": 60 * 1000, "h": 60 * 60 * 1000, "d": 24 * 60 * 60 * 1000, "w": 7 * 24 * 60 * 60 * 1000}
+	return value * units[matches[2]], true
+}
+
+/*
+  Suppresses the diff between relative times of the same length (e.g. -60m and -1h), as SignalFx may
+  return a different unit than the one in the configuration
+*/
+func suppressEquivalentRelativeTime(k string, old string, new string, d *schema.ResourceData) bool {
+	old_millis, old_ok := relativeTimeMillis(old)
+	new_millis, new_ok := relativeTimeMillis(new)
+	return old_ok && new_ok && old_millis == new_millis
+}
+
+/*
+  Suppresses the diff between the same time in seconds and in milliseconds since epoch: the API uses
+  milliseconds, the configuration seconds
+*/
+func suppressEquivalentEpoch(k string, old string, new string, d *schema.ResourceData) bool {
+	old_value, err := strconv.ParseInt(old, 10, 64)
+	if err != nil {
+		return false
+	}
+	new_value, err := strconv.ParseInt(new, 10, 64)
+	if err != nil {
+		return false
+	}
+	// Later than year 5138 in seconds, so it must be milliseconds
+	toSeconds := func(value int64) int64 {
+		if value > 100000000000 {
+			return value / 1000
+		}
+		return value
+	}
+	return toSeconds(old_value) == toSeconds(new_value)
+}
+
+/*
+  Checks the absolute time span of the start_time and end_time fields: both or neither must be set, and
+  the start must come before the end. The relative time_range is already exclusive with them
+  (ConflictsWith); this one needs both fields, so it's checked before sending the resource.
+*/
+func checkTimeSpan(d *schema.ResourceData) error {
+	return validateTimeSpan(d.Get("start_time").(int), d.Get("end_time").(int))
+}
+
+/*
+  Time options of a chart, from its time_range, or start_time and end_time
+*/
+func getChartTimeOptions(d *schema.ResourceData) map[string]interface{} {
+	timeMap := make(map[string]interface{})
+	if val, ok := d.GetOk("time_range"); ok {
+		if ms, err := fromRangeToMilliSeconds(val.(string)); err == nil {
+			timeMap["range"] = ms
+			timeMap["type"] = "relative"
+		}
+	}
+	if val, ok := d.GetOk("start_time"); ok {
+		timeMap["start"] = val.(int) * 1000
+		timeMap["type"] = "absolute"
+		if val, ok := d.GetOk("end_time"); ok {
+			timeMap["end"] = val.(int) * 1000
+		}
+	}
+	return timeMap
+}
+
+func validateTimeSpan(start_time int, end_time int) error {
+	if start_time == 0 && end_time == 0 {
+		return nil
+	}
+	if start_time == 0 || end_time == 0 {
+		return fmt.Errorf("start_time and end_time must be set together")
+	}
+	if start_time >= end_time {
+		return fmt.Errorf("start_time (%d) must be before end_time (%d)", start_time, end_time)
+	}
+	return nil
+}
+
+/*
+  Validates a description against the limits of the API, which otherwise rejects it with a bare 400: at
+  most DESCRIPTION_MAX_LENGTH characters, and no control characters other than newlines and tabs.
+*/
+func validateDescription(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	if length := utf8.RuneCountInString(value); length > DESCRIPTION_MAX_LENGTH {
+		errors = append(errors, fmt.Errorf("%s is %d characters long; must be at most %d", k, length, DESCRIPTION_MAX_LENGTH))
+	}
+	for _, char := range value {
+		if unicode.IsControl(char) && char != '\n' && char != '\r' && char != '\t' {
+			errors = append(errors, fmt.Errorf("%s has the control character %U; only newlines and tabs are allowed", k, char))
+			break
+		}
+	}
+	return
 }
 
 /*
@@ -87,13 +537,86 @@ func validateMaxDelayValue(v interface{}, k string) (we []string, errors []error
 }
 
 /*
-  Validates that sort_by field start with either + or -.
+  Validates the refresh_interval of list and single value charts; it must be at least one second
+*/
+func validateRefreshInterval(v interface{}, k string) (we []string, errors []error) {
+	value := v.(int)
+	if value < 1 {
+		errors = append(errors, fmt.Errorf("%d not allowed; %s must be >= 1", value, k))
+	}
+	return
+}
+
+/*
+  Validates the max_precision of list and single value charts; it must be at least one digit
+*/
+func validateMaxPrecision(v interface{}, k string) (we []string, errors []error) {
+	value := v.(int)
+	if value < 1 {
+		errors = append(errors, fmt.Errorf("%d not allowed; %s must be >= 1", value, k))
+	}
+	return
+}
+
+/*
+  Validates that sort_by field start with either + or -, followed by the property to sort by
+  (value, sf_metric or a dimension).
 */
 func validateSortBy(v interface{}, k string) (we []string, errors []error) {
 	value := v.(string)
 	if !strings.HasPrefix(value, "+") && !strings.HasPrefix(value, "-") {
 		errors = append(errors, fmt.Errorf("%s not allowed; must start either with + or - (ascending or descending)", value))
+	} else if property := value[1:]; strings.TrimSpace(property) != property || property == "" {
+		errors = append(errors, fmt.Errorf("%s not allowed; must be followed by the property to sort by (e.g. -value)", value))
+	}
+	return
+}
+
+/*
+  Validates the unit_prefix of charts: Metric (1000-based) or Binary (1024-based, e.g. for bytes)
+*/
+func validateUnitPrefix(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	if value != "Metric" && value != "Binary" {
+		errors = append(errors, fmt.Errorf("%s not allowed; must be either Metric or Binary", value))
+	}
+	return
+}
+
+/*
+  Validates the color_by of time and list charts
+*/
+func validateColorBy(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	if value != "Dimension" && value != "Metric" {
+		errors = append(errors, fmt.Errorf("%s not allowed; must be either Dimension or Metric", value))
+	}
+	return
+}
+
+/*
+  Validates the color_by of single value charts, which can also be colored by value (Scale)
+*/
+func validateSingleValueColorBy(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	if value != "Dimension" && value != "Metric" && value != "Scale" {
+		errors = append(errors, fmt.Errorf("%s not allowed; must be Dimension, Metric or Scale", value))
+	}
+	return
+}
+
+/*
+  Validates the secondary_visualization of list and single value charts
+*/
+func validateSecondaryVisualization(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	allowedWords := []string{"None", "Radial", "Linear", "Sparkline"}
+	for _, word := range allowedWords {
+		if value == word {
+			return
+		}
 	}
+	errors = append(errors, fmt.Errorf("%s not allowed; must be one of: %s", value, strings.Join(allowedWords, ", ")))
 	return
 }
 
@@ -105,6 +628,29 @@ func getColorScaleOptions(d *schema.ResourceData) []interface{} {
 	return getColorScaleOptionsFromSlice(colorScale)
 }
 
+/*
+  Checks the boundaries of the color_scale ranges: each one needs at least one, and can't have both the
+  inclusive and the non-inclusive one on the same side (e.g. gt and gte)
+*/
+func checkColorScale(colorScale []interface{}) error {
+	for _, scale := range colorScale {
+		scale := scale.(map[string]interface{})
+		set := func(field string) bool {
+			return scale[field].(float64) != math.MaxFloat32
+		}
+		if set("gt") && set("gte") {
+			return fmt.Errorf("The color_scale range of the color %s can't have both gt and gte", scale["color"])
+		}
+		if set("lt") && set("lte") {
+			return fmt.Errorf("The color_scale range of the color %s can't have both lt and lte", scale["color"])
+		}
+		if !set("gt") && !set("gte") && !set("lt") && !set("lte") {
+			return fmt.Errorf("The color_scale range of the color %s needs at least one of gt, gte, lt and lte", scale["color"])
+		}
+	}
+	return nil
+}
+
 func getColorScaleOptionsFromSlice(colorScale []interface{}) []interface{} {
 	item := make([]interface{}, len(colorScale))
 	if len(colorScale) == 0 {
@@ -139,13 +685,23 @@ func getColorScaleOptionsFromSlice(colorScale []interface{}) []interface{} {
 }
 
 /*
-  Send a GET to get the current state of the resource. It just checks if the lastUpdated timestamp is
-  later than the timestamp saved in the resource. If so, the resource has been modified in some way
-  in the UI, and should be recreated. This is signaled by setting synced to false, meaning if synced is set to
-  true in the tf configuration, it will update the resource to achieve the desired state.
+  Send a GET to get the current state of the resource. When the lastUpdated timestamp is later than the
+  timestamp saved in the resource, the resource has been modified in some way in the UI: the fields of
+  payload (what the state would send) are compared with the ones returned by SignalFx, and the ones that
+  differ are saved in drifted_fields. If any, or without a payload to compare, synced is set to false,
+  meaning if synced is set to true in the tf configuration, it will update the resource to achieve the
+  desired state.
+*/
+func resourceRead(config *signalformConfig, url string, sfxToken string, payload []byte, d *schema.ResourceData) error {
+	return resourceReadFields(config, url, sfxToken, payload, d, nil)
+}
+
+/*
+  Same as resourceRead, also passing the resource returned by SignalFx to setFields (when not nil), so the
+  resources that support it can copy it to their fields and show the changes made in the UI in the plan.
 */
-func resourceRead(url string, sfxToken string, d *schema.ResourceData) error {
-	status_code, resp_body, err := sendRequest("GET", url, sfxToken, nil)
+func resourceReadFields(config *signalformConfig, url string, sfxToken string, payload []byte, d *schema.ResourceData, setFields func(map[string]interface{}) error) error {
+	status_code, resp_body, err := sendRequest(config, "GET", url, sfxToken, nil)
 	if status_code == 200 {
 		mapped_resp := map[string]interface{}{}
 		err = json.Unmarshal(resp_body, &mapped_resp)
@@ -155,16 +711,32 @@ func resourceRead(url string, sfxToken string, d *schema.ResourceData) error {
 		// This implies the resource was modified in the Signalfx UI and therefore it is not synced with Signalform
 		last_updated := mapped_resp["lastUpdated"].(float64)
 		if last_updated > (d.Get("last_updated").(float64) + OFFSET) {
-			d.Set("synced", false)
+			drifted := []string{}
+			if payload != nil {
+				drifted, err = getDriftedFields(config, payload, mapped_resp)
+				if err != nil {
+					return fmt.Errorf("Failed comparing the resource %s with SignalFx: %s", d.Get("name"), err.Error())
+				}
+			}
+			if payload == nil || len(drifted) > 0 {
+				log.Printf("[INFO] The resource %s was changed outside of Terraform: %s", d.Get("name"), strings.Join(drifted, ", "))
+				d.Set("synced", false)
+			}
+			d.Set("drifted_fields", drifted)
 			d.Set("last_updated", last_updated)
 		}
 		var resource_url string
 		if val, ok := d.GetOk("resource_url"); ok {
-			resource_url = strings.Replace(fmt.Sprintf("%s", val), "<id>", mapped_resp["id"].(string), 1)
+			resource_url = resourceAppUrl(config, fmt.Sprintf("%s", val), mapped_resp["id"].(string))
 		} else {
 			resource_url = "DUMMY"
 		}
 		d.Set("url", resource_url)
+		if setFields != nil {
+			if err := setFields(mapped_resp); err != nil {
+				return fmt.Errorf("Failed reading the resource %s: %s", d.Get("name"), err.Error())
+			}
+		}
 	} else {
 		if status_code == 404 && strings.Contains(string(resp_body), " not found") {
 			// This implies that the resouce was deleted in the Signalfx UI and therefore we need to recreate it
@@ -177,11 +749,32 @@ func resourceRead(url string, sfxToken string, d *schema.ResourceData) error {
 	return nil
 }
 
+/*
+  Replaces "<id>" in the resource_url with the ID of the resource. The default app host is replaced with the one of
+  the configured realm, so that the default resource_url works for every realm
+*/
+func resourceAppUrl(config *signalformConfig, resource_url string, id string) string {
+	if config.AppURL != "" && strings.HasPrefix(resource_url, DEFAULT_APP_URL+"/") {
+		resource_url = config.AppURL + strings.TrimPrefix(resource_url, DEFAULT_APP_URL)
+	}
+	return strings.Replace(resource_url, "<id>", id, 1)
+}
+
 /*
   Fetches payload specified in terraform configuration and creates a resource
 */
-func resourceCreate(url string, sfxToken string, payload []byte, d *schema.ResourceData) error {
-	status_code, resp_body, err := sendRequest("POST", url, sfxToken, payload)
+func resourceCreate(config *signalformConfig, url string, sfxToken string, payload []byte, d *schema.ResourceData) error {
+	payload, err := addNameAffixes(config, payload)
+	if err != nil {
+		return err
+	}
+	if config.DryRun {
+		logDryRun("POST", url, payload, d)
+		d.SetId(fmt.Sprintf("%s%d", DRY_RUN_ID_PREFIX, time.Now().UnixNano()))
+		d.Set("synced", false)
+		return nil
+	}
+	status_code, resp_body, err := sendRequest(config, "POST", url, sfxToken, payload)
 	if status_code == 200 {
 		mapped_resp := map[string]interface{}{}
 		err = json.Unmarshal(resp_body, &mapped_resp)
@@ -192,7 +785,7 @@ func resourceCreate(url string, sfxToken string, payload []byte, d *schema.Resou
 		d.Set("last_updated", mapped_resp["lastUpdated"].(float64))
 		d.Set("synced", true)
 		// Replace "<id>" with the actual Resource ID
-		resource_url := strings.Replace(fmt.Sprintf("%s", d.Get("resource_url")), "<id>", mapped_resp["id"].(string), 1)
+		resource_url := resourceAppUrl(config, fmt.Sprintf("%s", d.Get("resource_url")), mapped_resp["id"].(string))
 		d.Set("url", resource_url)
 	} else {
 		return fmt.Errorf("For the resource %s SignalFx returned status %d: \n%s", d.Get("name"), status_code, resp_body)
@@ -203,8 +796,20 @@ func resourceCreate(url string, sfxToken string, payload []byte, d *schema.Resou
 /*
   Fetches payload specified in terraform configuration and creates chart
 */
-func resourceUpdate(url string, sfxToken string, payload []byte, d *schema.ResourceData) error {
-	status_code, resp_body, err := sendRequest("PUT", url, sfxToken, payload)
+func resourceUpdate(config *signalformConfig, url string, sfxToken string, payload []byte, d *schema.ResourceData) error {
+	if config.Protect {
+		return fmt.Errorf("protect is enabled, refusing to update the resource %s (%s)", d.Get("name"), d.Id())
+	}
+	payload, err := addNameAffixes(config, payload)
+	if err != nil {
+		return err
+	}
+	if config.DryRun {
+		logDryRun("PUT", url, payload, d)
+		d.Set("synced", false)
+		return nil
+	}
+	status_code, resp_body, err := sendRequest(config, "PUT", url, sfxToken, payload)
 	if status_code == 200 {
 		mapped_resp := map[string]interface{}{}
 		err = json.Unmarshal(resp_body, &mapped_resp)
@@ -214,7 +819,7 @@ func resourceUpdate(url string, sfxToken string, payload []byte, d *schema.Resou
 		// If the resource was updated successfully with Signalform configs, it is now synced with Signalfx
 		d.Set("synced", true)
 		d.Set("last_updated", mapped_resp["lastUpdated"].(float64))
-		resource_url := strings.Replace(fmt.Sprintf("%s", d.Get("resource_url")), "<id>", mapped_resp["id"].(string), 1)
+		resource_url := resourceAppUrl(config, fmt.Sprintf("%s", d.Get("resource_url")), mapped_resp["id"].(string))
 		d.Set("url", resource_url)
 	} else {
 		return fmt.Errorf("For the resource %s SignalFx returned status %d: \n%s", d.Get("name"), status_code, resp_body)
@@ -225,8 +830,15 @@ func resourceUpdate(url string, sfxToken string, payload []byte, d *schema.Resou
 /*
   Deletes a resource.  If the resource does not exist, it will receive a 404, and carry on as usual.
 */
-func resourceDelete(url string, sfxToken string, d *schema.ResourceData) error {
-	status_code, resp_body, err := sendRequest("DELETE", url, sfxToken, nil)
+func resourceDelete(config *signalformConfig, url string, sfxToken string, d *schema.ResourceData) error {
+	if config.Protect {
+		return fmt.Errorf("protect is enabled, refusing to delete the resource %s (%s)", d.Get("name"), d.Id())
+	}
+	if config.DryRun {
+		logDryRun("DELETE", url, nil, d)
+		return nil
+	}
+	status_code, resp_body, err := sendRequest(config, "DELETE", url, sfxToken, nil)
 	if err != nil {
 		return fmt.Errorf("Failed deleting resource  %s: %s", d.Get("name"), err.Error())
 	}
@@ -238,34 +850,258 @@ func resourceDelete(url string, sfxToken string, d *schema.ResourceData) error {
 	return nil
 }
 
+/*
+  Deletes a chart, unless it's on a dashboard archived by this run: then the chart is just forgotten,
+  so that the archived dashboard keeps it. Dashboards are destroyed before the charts they use.
+*/
+func chartDelete(config *signalformConfig, url string, sfxToken string, d *schema.ResourceData) error {
+	if config.isChartArchived(d.Id()) {
+		log.Printf("[INFO] The chart %s (%s) is on an archived dashboard, leaving it in SignalFx", d.Get("name"), d.Id())
+		d.SetId("")
+		return nil
+	}
+	return resourceDelete(config, url, sfxToken, d)
+}
+
+/*
+  IDs of charts, safe for concurrent use by the resources
+*/
+type chartIdSet struct {
+	mutex sync.Mutex
+	ids   map[string]bool
+}
+
+func newChartIdSet() *chartIdSet {
+	return &chartIdSet{ids: make(map[string]bool)}
+}
+
+/*
+  Records the charts of a dashboard being archived
+*/
+func (config *signalformConfig) addArchivedCharts(ids []string) {
+	if config.archivedCharts == nil {
+		config.archivedCharts = newChartIdSet()
+	}
+	config.archivedCharts.mutex.Lock()
+	defer config.archivedCharts.mutex.Unlock()
+	for _, id := range ids {
+		config.archivedCharts.ids[id] = true
+	}
+}
+
+func (config *signalformConfig) isChartArchived(id string) bool {
+	if config.archivedCharts == nil {
+		return false
+	}
+	config.archivedCharts.mutex.Lock()
+	defer config.archivedCharts.mutex.Unlock()
+	return config.archivedCharts.ids[id]
+}
+
+/*
+  Adds the name_prefix and name_suffix of the provider to the name in the payload
+*/
+func addNameAffixes(config *signalformConfig, payload []byte) ([]byte, error) {
+	if config.NamePrefix == "" && config.NameSuffix == "" {
+		return payload, nil
+	}
+	mapped_payload := map[string]interface{}{}
+	if err := json.Unmarshal(payload, &mapped_payload); err != nil {
+		return nil, fmt.Errorf("Failed unmarshaling the payload: %s", err.Error())
+	}
+	if name, ok := mapped_payload["name"].(string); ok {
+		mapped_payload["name"] = config.NamePrefix + name + config.NameSuffix
+	}
+	return json.Marshal(mapped_payload)
+}
+
+/*
+  Fields of the payload whose value differs in the resource returned by SignalFx, sorted. The API fills
+  in defaults, so only what the payload sets is compared.
+*/
+func getDriftedFields(config *signalformConfig, payload []byte, resource map[string]interface{}) ([]string, error) {
+	payload, err := addNameAffixes(config, payload)
+	if err != nil {
+		return nil, err
+	}
+	expected := map[string]interface{}{}
+	if err := json.Unmarshal(payload, &expected); err != nil {
+		return nil, fmt.Errorf("Failed unmarshaling the payload: %s", err.Error())
+	}
+	drifted := []string{}
+	for field, value := range expected {
+		if !matchesApiValue(value, resource[field]) {
+			drifted = append(drifted, field)
+		}
+	}
+	sort.Strings(drifted)
+	return drifted, nil
+}
+
+/*
+  Whether the value returned by SignalFx has everything of the expected one: the same scalars, the keys
+  of the objects (others can be there), and the items of the lists, in any order (sets have no order).
+  null matches a missing value.
+*/
+func matchesApiValue(expected interface{}, actual interface{}) bool {
+	switch expected := expected.(type) {
+	case map[string]interface{}:
+		actual, ok := actual.(map[string]interface{})
+		if !ok {
+			return len(expected) == 0 && actual == nil
+		}
+		for key, value := range expected {
+			if !matchesApiValue(value, actual[key]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		actual, ok := actual.([]interface{})
+		if !ok {
+			return len(expected) == 0 && actual == nil
+		}
+		if len(expected) != len(actual) {
+			return false
+		}
+		used := make([]bool, len(actual))
+		for _, value := range expected {
+			found := false
+			for i, item := range actual {
+				if !used[i] && matchesApiValue(value, item) {
+					used[i] = true
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	default:
+		return expected == actual
+	}
+}
+
+/*
+  Removes the name_prefix and name_suffix of the provider from a name returned by SignalFx
+*/
+func removeNameAffixes(config *signalformConfig, name string) string {
+	if strings.HasPrefix(name, config.NamePrefix) && strings.HasSuffix(name, config.NameSuffix) && len(name) >= len(config.NamePrefix)+len(config.NameSuffix) {
+		return name[len(config.NamePrefix) : len(name)-len(config.NameSuffix)]
+	}
+	return name
+}
+
+/*
+  With dry_run enabled, changes are only logged, so that a whole module can be applied to check the
+  payloads it generates. The created resources get a placeholder ID, and are marked as not synced:
+  the next apply without dry_run sends them for real.
+*/
+func logDryRun(method string, url string, payload []byte, d *schema.ResourceData) {
+	if payload == nil {
+		log.Printf("[INFO] dry_run: not sending %s %s for the resource %s", method, url, d.Get("name"))
+		return
+	}
+	log.Printf("[INFO] dry_run: not sending %s %s for the resource %s with payload:\n%s", method, url, d.Get("name"), payload)
+}
+
+/*
+  Tells whether the ID was set by a creation under dry_run, so nothing in SignalFx has it
+*/
+func isDryRunId(id string) bool {
+	return strings.HasPrefix(id, DRY_RUN_ID_PREFIX)
+}
+
 /*
 	Util method to get Legend Chart Options.
 */
 func getLegendOptions(d *schema.ResourceData) map[string]interface{} {
-	if properties, ok := d.GetOk("legend_fields_to_hide"); ok {
-		properties := properties.(*schema.Set).List()
-		legendOptions := make(map[string]interface{})
-		properties_opts := make([]map[string]interface{}, len(properties))
-		for i, property := range properties {
-			property := property.(string)
-			if property == "metric" {
-				property = "sf_originatingMetric"
-			} else if property == "plot_label" || property == "Plot Label" {
-				property = "sf_metric"
-			}
-			item := make(map[string]interface{})
-			item["property"] = property
-			item["enabled"] = false
-			properties_opts[i] = item
+	properties_opts := make([]map[string]interface{}, 0)
+	if fields, ok := d.GetOk("legend_options_fields"); ok {
+		for _, field := range fields.([]interface{}) {
+			field := field.(map[string]interface{})
+			properties_opts = append(properties_opts, map[string]interface{}{
+				"property": getLegendProperty(field["property"].(string)),
+				"enabled":  field["enabled"].(bool),
+			})
 		}
-		if len(properties_opts) > 0 {
-			legendOptions["fields"] = properties_opts
-			return legendOptions
+	} else if properties, ok := d.GetOk("legend_fields_to_hide"); ok {
+		for _, property := range properties.(*schema.Set).List() {
+			properties_opts = append(properties_opts, map[string]interface{}{
+				"property": getLegendProperty(property.(string)),
+				"enabled":  false,
+			})
 		}
 	}
+	if len(properties_opts) > 0 {
+		return map[string]interface{}{"fields": properties_opts}
+	}
 	return nil
 }
 
+/*
+  Name of a legend property for the API, which calls the metric sf_originatingMetric and the plot label
+  sf_metric
+*/
+func getLegendProperty(property string) string {
+	if property == "metric" {
+		return "sf_originatingMetric"
+	} else if property == "plot_label" || property == "Plot Label" {
+		return "sf_metric"
+	}
+	return property
+}
+
+/*
+  The columns of the legend, in the order they are shown
+*/
+func legendOptionsFieldsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:          schema.TypeList,
+		Optional:      true,
+		Description:   "Properties of the chart legend (i.e. dimension names, metric or plot_label), in the order to show them, and whether they are shown",
+		ConflictsWith: []string{"legend_fields_to_hide"},
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"property": &schema.Schema{
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "The property: a dimension name, metric or plot_label",
+				},
+				"enabled": &schema.Schema{
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     true,
+					Description: "(true by default) Whether the property is shown in the legend",
+				},
+			},
+		},
+	}
+}
+
+/*
+  Credential a resource is managed with, see resourceConfig
+*/
+func credentialSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      "auth_token",
+		ValidateFunc: validateCredential,
+		Description:  "Credential of the provider to manage the resource with: auth_token (the org token, by default) or session_token (the session token of the user)",
+	}
+}
+
+func validateCredential(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	if value != "auth_token" && value != "session_token" {
+		errors = append(errors, fmt.Errorf("%s not allowed; must be either auth_token or session_token", value))
+	}
+	return
+}
+
 /*
 	Util method to validate SignalFx specific string format.
 */
@@ -331,3 +1167,27 @@ func sanitizeProgramText(text string) string {
 	sane = r.ReplaceAllString(sane, "")
 	return sane
 }
+
+/*
+  Sets the services supported by an integration, minus the excluded ones, on a services data source
+*/
+func servicesRead(supported []string, d *schema.ResourceData) error {
+	services := filterServices(supported, d.Get("exclude").(*schema.Set).List())
+	d.SetId(fmt.Sprintf("%d", hashcode.String(strings.Join(services, ","))))
+	d.Set("services", services)
+	return nil
+}
+
+func filterServices(supported []string, excluded []interface{}) []string {
+	skip := make(map[string]bool)
+	for _, service := range excluded {
+		skip[service.(string)] = true
+	}
+	services := make([]string, 0, len(supported))
+	for _, service := range supported {
+		if !skip[service] {
+			services = append(services, service)
+		}
+	}
+	return services
+}
@@ -2,10 +2,15 @@ package signalform
 
 import (
 	"fmt"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -17,7 +22,7 @@ func TestSendRequestSuccess(t *testing.T) {
 	}))
 	defer server.Close()
 
-	status_code, body, err := sendRequest("GET", server.URL, "token", nil)
+	status_code, body, err := sendRequest(&signalformConfig{}, "GET", server.URL, "token", nil)
 	assert.Equal(t, 200, status_code)
 	assert.Equal(t, "Test Response\n", string(body))
 	assert.Nil(t, err)
@@ -28,7 +33,7 @@ func TestSendRequestResponseNotFound(t *testing.T) {
 	server := httptest.NewServer(http.NotFoundHandler())
 	defer server.Close()
 
-	status_code, body, err := sendRequest("POST", server.URL, "token", nil)
+	status_code, body, err := sendRequest(&signalformConfig{}, "POST", server.URL, "token", nil)
 	assert.Equal(t, 404, status_code)
 	assert.Contains(t, string(body), "page not found")
 	assert.Nil(t, err)
@@ -36,12 +41,413 @@ func TestSendRequestResponseNotFound(t *testing.T) {
 
 func TestSendRequestFail(t *testing.T) {
 	// Client will fail to send due to invalid URL
-	status_code, body, err := sendRequest("GET", "", "token", nil)
+	status_code, body, err := sendRequest(&signalformConfig{}, "GET", "", "token", nil)
 	assert.Equal(t, -1, status_code)
 	assert.Nil(t, body)
 	assert.Contains(t, err.Error(), "Failed sending GET request")
 }
 
+func TestSendRequestMalformedUrl(t *testing.T) {
+	status_code, _, err := sendRequest(&signalformConfig{}, "GET", "https://api.signalfx.com:443x/v2/chart", "token", nil)
+	assert.Equal(t, -1, status_code)
+	assert.Contains(t, err.Error(), "Failed creating GET request")
+
+	// The request can't be built, retrying it is pointless
+	start := time.Now()
+	_, _, err = sendRequest(&signalformConfig{MaxRetries: 3, RetryWaitMin: time.Hour, RetryWaitMax: time.Hour}, "GET", "https://api.signalfx.com:443x/v2/chart", "token", nil)
+	assert.Contains(t, err.Error(), "Failed creating GET request")
+	assert.True(t, time.Since(start) < time.Minute)
+}
+
+func TestSendRequestRetry(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(503)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	status_code, _, err := sendRequest(&signalformConfig{MaxRetries: 3}, "GET", server.URL, "token", nil)
+	assert.Equal(t, 200, status_code)
+	assert.Equal(t, 3, attempts)
+	assert.Nil(t, err)
+}
+
+func TestSendRequestRetryExhausted(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(502)
+	}))
+	defer server.Close()
+
+	status_code, _, err := sendRequest(&signalformConfig{MaxRetries: 2}, "PUT", server.URL, "token", nil)
+	assert.Equal(t, 502, status_code)
+	assert.Equal(t, 3, attempts)
+	assert.Nil(t, err)
+}
+
+func TestSendRequestNoRetryOnClientError(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(400)
+	}))
+	defer server.Close()
+
+	status_code, _, _ := sendRequest(&signalformConfig{MaxRetries: 3}, "POST", server.URL, "token", nil)
+	assert.Equal(t, 400, status_code)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestSendRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	config := &signalformConfig{HTTPClient: &http.Client{Timeout: 10 * time.Millisecond}}
+	status_code, _, err := sendRequest(config, "GET", server.URL, "token", nil)
+	assert.Equal(t, -1, status_code)
+	assert.Contains(t, err.Error(), "Failed sending GET request")
+}
+
+func TestSendRequestHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "token", r.Header.Get("X-SF-Token"))
+		assert.Equal(t, "terraform-provider-signalform", r.Header.Get("User-Agent"))
+	}))
+	defer server.Close()
+
+	sendRequest(&signalformConfig{}, "GET", server.URL, "token", nil)
+}
+
+func TestRequestHeadersUserAgentSuffix(t *testing.T) {
+	headers := requestHeaders(&signalformConfig{UserAgentSuffix: "ci/deploy-pipeline"}, "token", "text/plain")
+	assert.Equal(t, "terraform-provider-signalform ci/deploy-pipeline", headers.Get("User-Agent"))
+	assert.Equal(t, "text/plain", headers.Get("Content-Type"))
+}
+
+func TestRequestHeadersCustomHeaders(t *testing.T) {
+	config := &signalformConfig{CustomHeaders: map[string]string{"X-Gateway-Key": "secret", "X-SF-Token": "other"}}
+	headers := requestHeaders(config, "token", "application/json")
+	assert.Equal(t, "secret", headers.Get("X-Gateway-Key"))
+	assert.Equal(t, "token", headers.Get("X-SF-Token"))
+}
+
+func TestRedactToken(t *testing.T) {
+	assert.Equal(t, "****", redactToken(""))
+	assert.Equal(t, "****", redactToken("abcdefgh"))
+	assert.Equal(t, "****6789", redactToken("abcdefgh0123456789"))
+}
+
+func TestSendRequestNoRetryOnPostTimeout(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(504)
+	}))
+	defer server.Close()
+
+	// The object may have been created anyway
+	status_code, _, _ := sendRequest(&signalformConfig{MaxRetries: 3}, "POST", server.URL, "token", nil)
+	assert.Equal(t, 504, status_code)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestIsRetryable(t *testing.T) {
+	for _, method := range []string{"GET", "PUT", "DELETE", "POST"} {
+		assert.True(t, isRetryable(method, 429, nil), method)
+		assert.True(t, isRetryable(method, 503, nil), method)
+		assert.False(t, isRetryable(method, 400, nil), method)
+		assert.False(t, isRetryable(method, 200, nil), method)
+	}
+	for _, method := range []string{"GET", "PUT", "DELETE"} {
+		assert.True(t, isRetryable(method, 502, nil), method)
+		assert.True(t, isRetryable(method, 504, nil), method)
+		assert.True(t, isRetryable(method, -1, fmt.Errorf("timeout")), method)
+	}
+	assert.False(t, isRetryable("POST", 502, nil))
+	assert.False(t, isRetryable("POST", 504, nil))
+	assert.False(t, isRetryable("POST", -1, fmt.Errorf("timeout")))
+	assert.False(t, isRetryable("GET", -1, &requestError{"Failed creating GET request"}))
+}
+
+func TestSendRequestRetryAfter(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(429)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	// Retry-After wins over the backoff, which would take an hour here
+	start := time.Now()
+	status_code, _, err := sendRequest(&signalformConfig{MaxRetries: 1, RetryWaitMin: time.Hour, RetryWaitMax: time.Hour}, "POST", server.URL, "token", nil)
+	assert.Equal(t, 200, status_code)
+	assert.Equal(t, 2, attempts)
+	assert.Nil(t, err)
+	assert.True(t, time.Since(start) < time.Minute)
+
+	// Retry-After is clamped to RetryWaitMax
+	attempts = 0
+	clamped := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(429)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer clamped.Close()
+
+	start = time.Now()
+	status_code, _, err = sendRequest(&signalformConfig{MaxRetries: 1, RetryWaitMin: time.Millisecond, RetryWaitMax: 10 * time.Millisecond}, "GET", clamped.URL, "token", nil)
+	assert.Equal(t, 200, status_code)
+	assert.Equal(t, 2, attempts)
+	assert.Nil(t, err)
+	assert.True(t, time.Since(start) < time.Minute)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2018, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	wait, ok := parseRetryAfter("120", now)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, wait)
+
+	wait, ok = parseRetryAfter("Mon, 01 Jan 2018 12:00:30 GMT", now)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, wait)
+
+	wait, ok = parseRetryAfter("Mon, 01 Jan 2018 11:00:00 GMT", now)
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), wait)
+
+	_, ok = parseRetryAfter("", now)
+	assert.False(t, ok)
+	_, ok = parseRetryAfter("soon", now)
+	assert.False(t, ok)
+}
+
+func TestRetryWait(t *testing.T) {
+	config := &signalformConfig{RetryWaitMin: time.Second, RetryWaitMax: 5 * time.Second}
+	assert.Equal(t, time.Second, retryWait(config, 0))
+	assert.Equal(t, 2*time.Second, retryWait(config, 1))
+	assert.Equal(t, 4*time.Second, retryWait(config, 2))
+	assert.Equal(t, 5*time.Second, retryWait(config, 3))
+	assert.Equal(t, 5*time.Second, retryWait(config, 10))
+}
+
+func TestSendRequestConcurrencyLimit(t *testing.T) {
+	var mutex sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mutex.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mutex.Lock()
+		inFlight--
+		mutex.Unlock()
+	}))
+	defer server.Close()
+
+	config := &signalformConfig{RequestSlots: make(chan struct{}, 2)}
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sendRequest(config, "GET", server.URL, "token", nil)
+		}()
+	}
+	wg.Wait()
+	assert.True(t, maxInFlight <= 2)
+}
+
+func TestRetryWaitConstant(t *testing.T) {
+	config := &signalformConfig{RetryWaitMin: time.Second, RetryWaitMax: 5 * time.Second, RetryStrategy: "constant"}
+	assert.Equal(t, time.Second, retryWait(config, 0))
+	assert.Equal(t, time.Second, retryWait(config, 3))
+}
+
+func TestRetryWaitJitter(t *testing.T) {
+	config := &signalformConfig{RetryWaitMin: time.Second, RetryWaitMax: 5 * time.Second, RetryJitter: true}
+	for i := 0; i < 20; i++ {
+		wait := retryWait(config, 2)
+		assert.True(t, wait >= 2*time.Second && wait < 4*time.Second)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := newRateLimiter(100)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		limiter.wait()
+	}
+	// The first request goes through straight away, the others are spaced by 10ms
+	assert.True(t, time.Since(start) >= 40*time.Millisecond)
+}
+
+func TestResourceCreateDryRun(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, textChartResource().Schema, map[string]interface{}{"name": "foo", "markdown": "bar"})
+	err := resourceCreate(&signalformConfig{DryRun: true}, server.URL, "token", []byte(`{"name":"foo"}`), d)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, attempts)
+	assert.True(t, isDryRunId(d.Id()))
+
+	// The resources that use it go on
+	err = resourceUpdate(&signalformConfig{DryRun: true}, server.URL+"/"+d.Id(), "token", []byte(`{"name":"bar"}`), d)
+	assert.Nil(t, err)
+	err = resourceDelete(&signalformConfig{DryRun: true}, server.URL+"/"+d.Id(), "token", d)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, attempts)
+}
+
+func TestCheckChartsExistDryRun(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+	}))
+	defer server.Close()
+
+	assert.Nil(t, checkChartsExist(&signalformConfig{APIURL: server.URL, DryRun: true}, []string{DRY_RUN_ID_PREFIX + "1"}))
+	assert.Equal(t, 0, attempts)
+}
+
+func TestAddNameAffixes(t *testing.T) {
+	payload, err := addNameAffixes(&signalformConfig{NamePrefix: "[staging] ", NameSuffix: " (eu)"}, []byte(`{"name":"CPU","programText":"data('cpu')"}`))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"name":"[staging] CPU (eu)","programText":"data('cpu')"}`, string(payload))
+}
+
+func TestAddNameAffixesNotSet(t *testing.T) {
+	payload, err := addNameAffixes(&signalformConfig{}, []byte(`{"name":"CPU"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, `{"name":"CPU"}`, string(payload))
+}
+
+func TestApiUrl(t *testing.T) {
+	config := &signalformConfig{APIURL: "https://api.signalfx.com"}
+	assert.Equal(t, "https://api.signalfx.com/v2/chart", apiUrl(config, CHART_API_PATH))
+
+	config.APIVersion = "v3"
+	assert.Equal(t, "https://api.signalfx.com/v3/chart", apiUrl(config, CHART_API_PATH))
+
+	config.APIVersions = map[string]string{"dashboard": "v4"}
+	assert.Equal(t, "https://api.signalfx.com/v4/dashboard", apiUrl(config, DASHBOARD_API_PATH))
+	assert.Equal(t, "https://api.signalfx.com/v3/dashboardgroup", apiUrl(config, DASHBOARD_GROUP_API_PATH))
+}
+
+func TestResourceAppUrl(t *testing.T) {
+	config := &signalformConfig{AppURL: "https://app.eu0.signalfx.com"}
+	assert.Equal(t, "https://app.eu0.signalfx.com/#/chart/ABC", resourceAppUrl(config, CHART_URL, "ABC"))
+	assert.Equal(t, "https://app.eu0.signalfx.com/#/dashboard/ABC", resourceAppUrl(config, DASHBOARD_URL, "ABC"))
+	assert.Equal(t, "https://app.eu0.signalfx.com/#/detector/v2/ABC/edit", resourceAppUrl(config, DETECTOR_URL, "ABC"))
+	// A resource_url set on another host is kept
+	assert.Equal(t, "https://signalfx.example.com/#/chart/ABC", resourceAppUrl(config, "https://signalfx.example.com/#/chart/<id>", "ABC"))
+
+	assert.Equal(t, "https://app.signalfx.com/#/chart/ABC", resourceAppUrl(&signalformConfig{}, CHART_URL, "ABC"))
+}
+
+func TestApiPathNoVersion(t *testing.T) {
+	assert.Equal(t, "/v2/signalflow", apiPath(&signalformConfig{}, SIGNALFLOW_API_PATH))
+	assert.Equal(t, "/v2", apiPath(&signalformConfig{APIVersion: "v3"}, "/v2"))
+}
+
+func TestResourceDeleteProtect(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, textChartResource().Schema, map[string]interface{}{"name": "foo", "markdown": "bar"})
+	err := resourceDelete(&signalformConfig{Protect: true}, server.URL, "token", d)
+	assert.Contains(t, err.Error(), "protect is enabled, refusing to delete")
+	assert.Equal(t, 0, attempts)
+}
+
+func TestChartDeleteArchive(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+	}))
+	defer server.Close()
+
+	config := &signalformConfig{ArchiveDashboardGroupID: "GROUPID"}
+	config.addArchivedCharts([]string{"ARCHIVED"})
+
+	// The charts of an archived dashboard are left in place
+	d := schema.TestResourceDataRaw(t, textChartResource().Schema, map[string]interface{}{"name": "foo", "markdown": "bar"})
+	d.SetId("ARCHIVED")
+	err := chartDelete(config, server.URL, "token", d)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, attempts)
+	assert.Equal(t, "", d.Id())
+
+	// The others are deleted
+	d.SetId("OTHER")
+	err = chartDelete(config, server.URL, "token", d)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, attempts)
+	assert.Equal(t, "", d.Id())
+}
+
+func TestGetApiResultsPaging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("offset") == "0" {
+			fmt.Fprintln(w, `{"count":3,"results":[{"id":"A"},{"id":"B"}]}`)
+		} else {
+			fmt.Fprintln(w, `{"count":3,"results":[{"id":"C"}]}`)
+		}
+	}))
+	defer server.Close()
+
+	results, err := getApiResults(&signalformConfig{}, server.URL+"?name=foo", "token")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(results))
+	assert.Equal(t, "C", results[2]["id"])
+}
+
+func TestGetApiResourceError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	response := map[string]interface{}{}
+	err := getApiResource(&signalformConfig{}, server.URL, "token", &response)
+	assert.Contains(t, err.Error(), "SignalFx returned status 404")
+}
+
+func TestGetStringMap(t *testing.T) {
+	value := map[string]interface{}{"sha": "abc", "number": 12.0, "ok": true}
+	expected := map[string]interface{}{"sha": "abc", "number": "12", "ok": "true"}
+	assert.Equal(t, expected, getStringMap(value))
+	assert.Equal(t, map[string]interface{}{}, getStringMap(nil))
+}
+
 func TestValidateSignalfxRelativeTimeMinutes(t *testing.T) {
 	_, errors := validateSignalfxRelativeTime("-5m", "time_range")
 	assert.Equal(t, 0, len(errors))
@@ -73,6 +479,29 @@ func TestConversionSignalfxrealtiveTimeIntoMs(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestValidateUnitPrefix(t *testing.T) {
+	for _, value := range []string{"Metric", "Binary"} {
+		_, errors := validateUnitPrefix(value, "unit_prefix")
+		assert.Equal(t, 0, len(errors), value)
+	}
+	_, errors := validateUnitPrefix("binary", "unit_prefix")
+	assert.Equal(t, 1, len(errors))
+}
+
+func TestValidateRefreshInterval(t *testing.T) {
+	_, errors := validateRefreshInterval(60, "refresh_interval")
+	assert.Equal(t, 0, len(errors))
+	_, errors = validateRefreshInterval(0, "refresh_interval")
+	assert.Equal(t, 1, len(errors))
+}
+
+func TestValidateMaxPrecision(t *testing.T) {
+	_, errors := validateMaxPrecision(3, "max_precision")
+	assert.Equal(t, 0, len(errors))
+	_, errors = validateMaxPrecision(-1, "max_precision")
+	assert.Equal(t, 1, len(errors))
+}
+
 func TestValidateSortByAscending(t *testing.T) {
 	_, errors := validateSortBy("+foo", "sort_by")
 	assert.Equal(t, 0, len(errors))
@@ -88,6 +517,13 @@ func TestValidateSortByNoDirection(t *testing.T) {
 	assert.Equal(t, 1, len(errors))
 }
 
+func TestValidateSortByNoProperty(t *testing.T) {
+	for _, value := range []string{"+", "-", "- value", "+value "} {
+		_, errors := validateSortBy(value, "sort_by")
+		assert.Equal(t, 1, len(errors), value)
+	}
+}
+
 func TestSanitizeProgramTextSane(t *testing.T) {
 	text := "previous = data('statmonster.inbound_lines',filter('source_region','${var.clusters_no_uswest2[count.index]}')).timeshift('2m').sum()\nsignal = data('statmonster.inbo    und_lines',filter('source_region','${var.clusters_no_uswest2[count.index]}')).sum()\ndetect('Low number of log lines', when(signal < (previous * 0.50), '2m', 0.90))"
 	assert.Equal(t, text, sanitizeProgramText(text))
@@ -116,3 +552,138 @@ func TestCorrectColorValue(t *testing.T) {
 	assert.Equal(t, 7, ret["paletteIndex"])
 
 }
+
+func TestFilterServices(t *testing.T) {
+	supported := []string{"AWS/EC2", "AWS/ELB", "AWS/Billing"}
+	assert.Equal(t, supported, filterServices(supported, []interface{}{}))
+	assert.Equal(t, []string{"AWS/EC2", "AWS/ELB"}, filterServices(supported, []interface{}{"AWS/Billing", "AWS/Unknown"}))
+}
+
+func TestGetApiStrings(t *testing.T) {
+	assert.Equal(t, []interface{}{"a", "b"}, getApiStrings([]interface{}{"a", "b"}))
+	assert.Equal(t, []interface{}{"a"}, getApiStrings("a"))
+	assert.Equal(t, []interface{}{}, getApiStrings(""))
+	assert.Equal(t, []interface{}{}, getApiStrings(nil))
+}
+
+func TestRemoveNameAffixes(t *testing.T) {
+	config := &signalformConfig{NamePrefix: "[staging] ", NameSuffix: " (tf)"}
+	assert.Equal(t, "My chart", removeNameAffixes(config, "[staging] My chart (tf)"))
+	// Renamed in the UI without the affixes
+	assert.Equal(t, "My chart", removeNameAffixes(config, "My chart"))
+	assert.Equal(t, "My chart", removeNameAffixes(&signalformConfig{}, "My chart"))
+}
+
+func TestRelativeTimeMillis(t *testing.T) {
+	millis, ok := relativeTimeMillis("-1h")
+	assert.True(t, ok)
+	assert.Equal(t, int64(3600000), millis)
+	_, ok = relativeTimeMillis("Now")
+	assert.False(t, ok)
+}
+
+func TestSuppressEquivalentRelativeTime(t *testing.T) {
+	assert.True(t, suppressEquivalentRelativeTime("time_range", "-1h", "-60m", nil))
+	assert.True(t, suppressEquivalentRelativeTime("time_range", "-1w", "-7d", nil))
+	assert.False(t, suppressEquivalentRelativeTime("time_range", "-1h", "-15m", nil))
+	assert.False(t, suppressEquivalentRelativeTime("time_range", "", "-15m", nil))
+}
+
+func TestSuppressEquivalentEpoch(t *testing.T) {
+	assert.True(t, suppressEquivalentEpoch("start_time", "1500000000000", "1500000000", nil))
+	assert.True(t, suppressEquivalentEpoch("start_time", "1500000000", "1500000000", nil))
+	assert.False(t, suppressEquivalentEpoch("start_time", "1500000000000", "1500003600", nil))
+	assert.False(t, suppressEquivalentEpoch("start_time", "", "1500003600", nil))
+}
+
+func TestGetDriftedFields(t *testing.T) {
+	config := &signalformConfig{NamePrefix: "[tf] "}
+	payload := []byte(`{"name": "My chart", "programText": "A", "options": {"type": "List"}, "tags": ["a", "b"], "description": null}`)
+	resource := map[string]interface{}{
+		"name":        "[tf] My chart",
+		"programText": "A",
+		// Defaults filled in by the API
+		"options":  map[string]interface{}{"type": "List", "unitPrefix": "Metric"},
+		"tags":     []interface{}{"b", "a"},
+		"creator":  "XXX",
+		"packages": "",
+	}
+	drifted, err := getDriftedFields(config, payload, resource)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{}, drifted)
+
+	resource["programText"] = "B"
+	resource["options"] = map[string]interface{}{"type": "TimeSeriesChart"}
+	resource["tags"] = []interface{}{"a"}
+	drifted, err = getDriftedFields(config, payload, resource)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"options", "programText", "tags"}, drifted)
+}
+
+func TestMatchesApiValue(t *testing.T) {
+	assert.True(t, matchesApiValue(nil, nil))
+	assert.True(t, matchesApiValue([]interface{}{}, nil))
+	assert.False(t, matchesApiValue([]interface{}{"a"}, nil))
+	assert.True(t, matchesApiValue([]interface{}{"a", "a", "b"}, []interface{}{"a", "b", "a"}))
+	assert.False(t, matchesApiValue([]interface{}{"a", "a", "b"}, []interface{}{"a", "b", "b"}))
+	assert.False(t, matchesApiValue(float64(1), "1"))
+}
+
+func TestValidateTimeSpan(t *testing.T) {
+	assert.Nil(t, validateTimeSpan(0, 0))
+	assert.Nil(t, validateTimeSpan(1500000000, 1500003600))
+	assert.Equal(t, "start_time and end_time must be set together", validateTimeSpan(1500000000, 0).Error())
+	assert.Equal(t, "start_time and end_time must be set together", validateTimeSpan(0, 1500003600).Error())
+	assert.Equal(t, "start_time (1500003600) must be before end_time (1500000000)", validateTimeSpan(1500003600, 1500000000).Error())
+	assert.NotNil(t, validateTimeSpan(1500000000, 1500000000))
+}
+
+func TestValidateDescription(t *testing.T) {
+	_, errors := validateDescription("Latency of the *API*\n\tby host", "description")
+	assert.Equal(t, 0, len(errors))
+	_, errors = validateDescription(strings.Repeat("é", DESCRIPTION_MAX_LENGTH), "description")
+	assert.Equal(t, 0, len(errors))
+	_, errors = validateDescription(strings.Repeat("a", DESCRIPTION_MAX_LENGTH+1), "description")
+	assert.Equal(t, "description is 1025 characters long; must be at most 1024", errors[0].Error())
+	_, errors = validateDescription("Latency\x00", "description")
+	assert.Equal(t, "description has the control character U+0000; only newlines and tabs are allowed", errors[0].Error())
+}
+
+func TestGetLegendProperty(t *testing.T) {
+	assert.Equal(t, "sf_originatingMetric", getLegendProperty("metric"))
+	assert.Equal(t, "sf_metric", getLegendProperty("plot_label"))
+	assert.Equal(t, "sf_metric", getLegendProperty("Plot Label"))
+	assert.Equal(t, "host", getLegendProperty("host"))
+}
+
+func TestValidateSecondaryVisualization(t *testing.T) {
+	for _, value := range []string{"None", "Radial", "Linear", "Sparkline"} {
+		_, errors := validateSecondaryVisualization(value, "secondary_visualization")
+		assert.Equal(t, 0, len(errors))
+	}
+	_, errors := validateSecondaryVisualization("Gauge", "secondary_visualization")
+	assert.Equal(t, 1, len(errors))
+}
+
+func TestValidateColorBy(t *testing.T) {
+	_, errors := validateColorBy("Dimension", "color_by")
+	assert.Equal(t, 0, len(errors))
+	_, errors = validateColorBy("Scale", "color_by")
+	assert.Equal(t, 1, len(errors))
+	_, errors = validateSingleValueColorBy("Scale", "color_by")
+	assert.Equal(t, 0, len(errors))
+	_, errors = validateSingleValueColorBy("dimension", "color_by")
+	assert.Equal(t, 1, len(errors))
+}
+
+func TestCheckColorScale(t *testing.T) {
+	unset := float64(math.MaxFloat32)
+	scale := func(gt, gte, lt, lte float64) map[string]interface{} {
+		return map[string]interface{}{"gt": gt, "gte": gte, "lt": lt, "lte": lte, "color": "green"}
+	}
+	assert.Nil(t, checkColorScale([]interface{}{scale(60, unset, unset, unset), scale(unset, unset, unset, 60)}))
+	assert.Nil(t, checkColorScale([]interface{}{scale(10, unset, 60, unset)}))
+	assert.Equal(t, "The color_scale range of the color green can't have both gt and gte", checkColorScale([]interface{}{scale(60, 60, unset, unset)}).Error())
+	assert.Equal(t, "The color_scale range of the color green can't have both lt and lte", checkColorScale([]interface{}{scale(unset, unset, 60, 60)}).Error())
+	assert.NotNil(t, checkColorScale([]interface{}{scale(unset, unset, unset, unset)}))
+}